@@ -0,0 +1,121 @@
+package rql
+
+// FilterNode is a node in the filter tree returned by ParseAST. A node is either a logical group
+// of child nodes (AND, OR or NOT), or a predicate that applies its operator on a field. For example,
+// the filter { "$or": [{ "age": { "$gt": 10 } }, { "name": "a8m" }] } is parsed into:
+//
+//	AND
+//	└── OR
+//	    ├── AND
+//	    │   └── age GT 10
+//	    └── AND
+//	        └── name EQ "a8m"
+//
+// Each JSON object is an AND group, and a field with more than one operator is an AND group of its
+// predicates.
+type FilterNode struct {
+	// Op is the operator of a logical group (AND, OR or NOT), or the operator of a predicate (i.e. EQ,
+	// GT or BETWEEN). A `"$null": false` predicate uses the NOTNULL operator.
+	Op Op
+	// Children holds the nodes of a logical group. A NOT group has exactly one child.
+	Children []*FilterNode
+	// Field is the field of a predicate, and nil for logical groups.
+	Field *FieldMeta
+	// Values holds the converted operands of a predicate. For example, one value for EQ (or a slice for IN),
+	// two values for BETWEEN, and none for NULL.
+	Values []interface{}
+	// Exp is the SQL expression of a predicate with a `?` placeholder for each of its arguments (see Args),
+	// regardless of the configured parameter symbol. For example: "age > ?", or "age BETWEEN ? AND ?".
+	Exp string
+	// bare is true for groups that are rendered without parentheses, i.e. the filter objects.
+	bare bool
+}
+
+// IsPredicate reports whether the node is a predicate (a leaf of the tree).
+func (n *FilterNode) IsPredicate() bool {
+	return n.Field != nil
+}
+
+// Args returns the query arguments of a predicate, matching the placeholders of its Exp. Unlike Values, the
+// list of the IN and NIN operators is expanded to an argument per element, i.e. "age IN (?, ?)".
+func (n *FilterNode) Args() []interface{} {
+	if n.Op == IN || n.Op == NIN {
+		if vs, ok := n.Values[0].([]interface{}); ok && len(n.Values) == 1 {
+			return vs
+		}
+	}
+	return n.Values
+}
+
+// ParseAST parses the filter of the given buffer into a tree of FilterNode. It applies the same
+// validation and conversion rules as Parse, and allows rendering the filter for non-SQL backends.
+func (p *Parser) ParseAST(b []byte) (n *FilterNode, err error) {
+	q := &Query{}
+	if err := q.UnmarshalJSON(b); err != nil {
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = perr
+			n = nil
+		}
+	}()
+	ps := p.newParseState()
+	n = ps.filter(q)
+	parseStatePool.Put(ps)
+	return n, nil
+}
+
+// filter builds the tree of the query filter, combined with the default filter.
+func (p *parseState) filter(q *Query) *FilterNode {
+	n := p.and(q.Filter)
+	p.raise()
+	if q.Negate && len(n.Children) > 0 {
+		n = &FilterNode{Op: NOT, Children: []*FilterNode{n}}
+	}
+	expectField(p.MaxFilterFields == 0 || len(p.usedOps) <= p.MaxFilterFields, ErrLimitExceeded, "filter", nil, "filter must reference at most %d distinct fields, got %d", p.MaxFilterFields, len(p.usedOps))
+	if len(p.DefaultFilter) > 0 {
+		// the limits apply only on the caller filter.
+		p.trusted = true
+		d := p.and(p.DefaultFilter)
+		p.trusted = false
+		switch {
+		case len(n.Children) == 0:
+		case n.Op == NOT:
+			d.Children = append(d.Children, n)
+		default:
+			d.Children = append(d.Children, n.Children...)
+		}
+		n = d
+	}
+	if p.RejectMatchAll && !n.hasPredicate() {
+		must(ErrMatchAll, ErrInvalidQuery, "", nil, "invalid filter")
+	}
+	return n
+}
+
+// add appends the given child to the group, unless it is an empty group. Empty groups (i.e. {} or
+// { "$or": [] }) have no conditions, and are ignored in order to avoid empty parentheses and dangling
+// operators in the rendered expression.
+func (n *FilterNode) add(c *FilterNode) {
+	if c.IsPredicate() || len(c.Children) > 0 {
+		n.Children = append(n.Children, c)
+	}
+}
+
+// hasPredicate reports whether the tree of the node contains a predicate.
+func (n *FilterNode) hasPredicate() bool {
+	if n.IsPredicate() {
+		return true
+	}
+	for _, c := range n.Children {
+		if c.hasPredicate() {
+			return true
+		}
+	}
+	return false
+}
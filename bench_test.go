@@ -108,3 +108,25 @@ func BenchmarkSmallQuery(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkParallelQuery(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, err := p.Parse([]byte(`{
+		"filter": {
+			"name": "foo",
+			"$or": [
+				{ "age": { "$gt": 20 } },
+				{ "work.name": { "$like": "bar" } }
+			]
+		},
+		"sort": [ "-age" ],
+		"limit": 10
+	}`))
+			if err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
@@ -2,8 +2,10 @@ package rql
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"reflect"
+	"time"
 )
 
 // Op is a filter operator used by rql.
@@ -12,30 +14,76 @@ type Direction byte
 
 // Operators that support by rql.
 const (
-	ASC  = Direction('+')
-	DESC = Direction('-')
-	EQ   = Op("eq")   // =
-	NEQ  = Op("neq")  // <>
-	LT   = Op("lt")   // <
-	GT   = Op("gt")   // >
-	LTE  = Op("lte")  // <=
-	GTE  = Op("gte")  // >=
-	LIKE = Op("like") // LIKE "PATTERN"
-	OR   = Op("or")   // disjunction
-	AND  = Op("and")  // conjunction
+	ASC        = Direction('+')
+	DESC       = Direction('-')
+	EQ         = Op("eq")         // =
+	NEQ        = Op("neq")        // <>
+	LT         = Op("lt")         // <
+	GT         = Op("gt")         // >
+	LTE        = Op("lte")        // <=
+	GTE        = Op("gte")        // >=
+	LIKE       = Op("like")       // LIKE "PATTERN"
+	ILIKE      = Op("ilike")      // ILIKE "PATTERN"
+	STARTSWITH = Op("startswith") // LIKE "VALUE%" ESCAPE '\'
+	ENDSWITH   = Op("endswith")   // LIKE "%VALUE" ESCAPE '\'
+	INCLUDES   = Op("includes")   // LIKE "%VALUE%" ESCAPE '\'
+	IN         = Op("in")         // IN (?, ?)
+	NIN        = Op("nin")        // NOT IN (?, ?)
+	OR         = Op("or")         // disjunction
+	AND        = Op("and")        // conjunction
+	NOT        = Op("not")        // negation
+	BETWEEN    = Op("between")    // BETWEEN ? AND ?
+	SIZE       = Op("size")       // cardinality(array) = ?
+	SEARCH     = Op("search")     // to_tsvector(column) @@ plainto_tsquery(?)
+	HAS        = Op("has")        // ? = ANY(array)
+	CONTAINS   = Op("contains")   // array @> ?
+	OVERLAPS   = Op("overlaps")   // (start, end) OVERLAPS (?, ?)
+	NULL       = Op("null")       // IS NULL / IS NOT NULL
+	NOTNULL    = Op("notnull")    // IS NOT NULL, rendered when $null is false
+	JSONPATH   = Op("jsonpath")   // metadata->>'tier', the extraction of JSON path fields
+	INSUBNET   = Op("insubnet")   // ip << ?, the containment of an IP address in a CIDR subnet
+)
+
+// Nulls is the placement of NULL values in a sort expression.
+type Nulls string
+
+// Null placements that support by rql. For example, "-created_at nullslast".
+const (
+	NullsFirst = Nulls("nullsfirst")
+	NullsLast  = Nulls("nullslast")
+)
+
+// SortMerge is the strategy for combining the DefaultSort with the requested sort.
+type SortMerge string
+
+// Sort merge strategies that support by rql.
+const (
+	SortMergeReplace = SortMerge("")
+	SortMergeAppend  = SortMerge("append")
+)
+
+// Dialect is the SQL dialect used for rendering the parser output.
+type Dialect string
+
+// Dialects that support by rql.
+const (
+	DialectDefault = Dialect("")
+	DialectOracle  = Dialect("oracle")
 )
 
 // Default values for configuration.
 const (
-	DefaultTagName     = "rql"
-	DefaultOpPrefix    = "$"
-	DefaultFieldSep    = "_"
-	DefaultLimit       = 25
-	DefaultMaxLimit    = 100
-	Offset             = "offset"
-	Limit              = "limit"
-	DefaultParamOffset = 1
-	DefaultParamSymbol = "?"
+	DefaultTagName      = "rql"
+	DefaultOpPrefix     = "$"
+	DefaultFieldSep     = "_"
+	DefaultLimit        = 25
+	DefaultMaxLimit     = 100
+	Offset              = "offset"
+	Limit               = "limit"
+	DefaultParamOffset  = 1
+	DefaultParamSymbol  = "?"
+	DefaultNamedSymbol  = ":"
+	DefaultMaxBodyBytes = 1 << 20
 )
 
 var (
@@ -47,16 +95,47 @@ var (
 		ASC:  "asc",
 		DESC: "desc",
 	}
+	// directions of the "dir" option in the tag.
+	sortDirs = map[string]Direction{
+		"asc":  ASC,
+		"desc": DESC,
+	}
+	nullsFormat = map[Nulls]string{
+		NullsFirst: "NULLS FIRST",
+		NullsLast:  "NULLS LAST",
+	}
+	// operators that can be applied by default on bare string filters, i.e. { "name": "foo" }.
+	defaultStringOps = map[Op]bool{
+		EQ:    true,
+		LIKE:  true,
+		ILIKE: true,
+	}
 	opFormat = map[Op]string{
-		EQ:   "=",
-		NEQ:  "<>",
-		LT:   "<",
-		GT:   ">",
-		LTE:  "<=",
-		GTE:  ">=",
-		LIKE: "LIKE",
-		OR:   "OR",
-		AND:  "AND",
+		EQ:         "=",
+		NEQ:        "<>",
+		LT:         "<",
+		GT:         ">",
+		LTE:        "<=",
+		GTE:        ">=",
+		LIKE:       "LIKE",
+		ILIKE:      "ILIKE",
+		STARTSWITH: "LIKE",
+		ENDSWITH:   "LIKE",
+		INCLUDES:   "LIKE",
+		IN:         "IN",
+		NIN:        "NOT IN",
+		OR:         "OR",
+		AND:        "AND",
+		NOT:        "NOT",
+		BETWEEN:    "BETWEEN",
+		SIZE:       "cardinality",
+		SEARCH:     "@@",
+		HAS:        "= ANY",
+		CONTAINS:   "@>",
+		OVERLAPS:   "OVERLAPS",
+		NULL:       "IS NULL",
+		NOTNULL:    "IS NOT NULL",
+		INSUBNET:   "<<",
 	}
 )
 
@@ -69,8 +148,23 @@ func GetAllOps() []Op {
 		LTE,
 		GTE,
 		LIKE,
+		ILIKE,
+		STARTSWITH,
+		ENDSWITH,
+		INCLUDES,
+		IN,
+		NIN,
 		OR,
 		AND,
+		NOT,
+		BETWEEN,
+		SIZE,
+		SEARCH,
+		HAS,
+		CONTAINS,
+		OVERLAPS,
+		NULL,
+		INSUBNET,
 	}
 }
 
@@ -156,30 +250,250 @@ type Config struct {
 	// LimitMaxValue is the upper boundary for the limit field. User will get an error if the given value is greater
 	// than this value. It defaults to 100.
 	LimitMaxValue int
+	// OffsetMaxValue is the upper boundary for the offset field (including the offset of a page), in order to
+	// prevent deep scans of the table. User will get an error if the given value is greater than this value.
+	// It defaults to 0 (no limit).
+	OffsetMaxValue int
+	// AllowUnlimited if true allows queries without a limit (or pageSize) to return all rows. In this case, the
+	// `Limit` field of the output is 0, the DefaultLimit is not applied, and the limit clause is omitted from the
+	// `Params.SQL` output. The LimitMaxValue is still enforced on given limits. It defaults to false.
+	AllowUnlimited bool
 	// DefaultSort is the default value for the 'Sort' field that returns when no sort expression is supplied by the caller.
-	// It defaults to an empty string slice.
+	// Its fields are resolved like the requested ones (i.e. "-address.name" with a "." FieldSep), and they are
+	// validated against the model by NewParser. It defaults to an empty string slice.
 	DefaultSort []string
+	// DefaultSortMerge is the strategy for combining the DefaultSort with a non-empty requested sort. It defaults to
+	// SortMergeReplace, which ignores the DefaultSort. SortMergeAppend appends the DefaultSort fields that are not
+	// already sorted, as secondary sort keys. For example, ["-name"] renders "name desc, created_at desc" for a
+	// DefaultSort of ["-created_at", "name"].
+	DefaultSortMerge SortMerge
+	// DedupSort if true ignores the repeated fields of the sort expression, keeping their first occurrence. For
+	// example, ["age", "-age"] renders "age". By default, repeated sort fields are rejected with an error.
+	DedupSort bool
+	// MaxSortFields is the maximum number of fields in the sort expression of a query, in order to prevent expensive
+	// sorts on columns without matching indexes. The DefaultSort and the SortTiebreaker fields are not counted.
+	// It defaults to 0 (no limit).
+	MaxSortFields int
+	// DefaultFilter is a filter object that is combined using AND with every filter supplied by the caller, i.e. for
+	// scoping the queries to a tenant or excluding soft-deleted rows. It is not affected by the `negate` field of the
+	// query, nor by the filter limits (i.e. MaxFilterConditions) and the AllowedOps of the fields, and it is validated
+	// when the parser is created. For example:
+	//
+	//	DefaultFilter: map[string]interface{}{"deleted_at": map[string]interface{}{"$null": true}}
+	//
+	// renders "deleted_at IS NULL AND name = ?" for {"name": "a8m"}, and "deleted_at IS NULL" for an empty filter.
+	DefaultFilter map[string]interface{}
+	// RejectMatchAll if true rejects the queries whose filter has no conditions (including the DefaultFilter), i.e. an
+	// empty filter or { "$or": [] }, with an error that wraps ErrMatchAll. It protects the destructive statements (DELETE
+	// or UPDATE) that reuse the filter expression from operating on the whole table. It defaults to false.
+	RejectMatchAll bool
+	// CoerceStringBool if true accepts string operands on bool fields (i.e. "true" or "false"), for clients that can
+	// send only strings. The strings are parsed using strconv.ParseBool. By default, they are rejected with an error.
+	CoerceStringBool bool
+	// CollectErrors if true collects the errors of all filter fields (i.e. unknown fields or invalid values) instead
+	// of failing on the first one, and returns them together as ParseErrors, wrapped by the returned ParseError.
+	// It defaults to false.
+	CollectErrors bool
+	// AllowEmptyGroups if true ignores the empty `$or` and `$and` arrays in the filter, i.e. { "$or": [], "age": 1 } is
+	// translated to "age = ?". By default, they are rejected with an error.
+	AllowEmptyGroups bool
+	// SortTiebreaker is a list of sort expressions that are appended to every non-empty sort clause (the requested
+	// one or the DefaultSort), unless their field is already sorted. For example, []string{"id"} renders "name desc, id"
+	// for ["-name"]. Using a unique column makes the order total, which is required for stable pagination.
+	// The expressions are validated against the model by NewParser.
+	SortTiebreaker []string
+	// SortNulls is the placement of NULL values for nullable fields (pointers and `sql.Null*` types) in the sort clause,
+	// when it is not set by the sort expression or by the "nulls" option in the struct tag. Combined with SortTiebreaker,
+	// it makes the ORDER BY clause deterministic. It defaults to "", which leaves the placement to the database.
+	SortNulls Nulls
 	// Lets the user define how a rql op is translated to a db op. // Returns db operator and statement format string.
 	// TODO: I think this interface can be improved, I'm not sure exactly yet, need more use cases.
 	// Current edge case requiring format string is the `= any (?)` op. Any expects `()` around ? for casting over.
 	// Providing a format string fixes that, but is not very flexible, a template would be better.
+	// The NULL and NOTNULL ops take no parameter, so their format string receives only the column and the db operator,
+	// The ILIKE op is not supported by all databases, a MySQL user may translate it to "LOWER(%v) LIKE LOWER(%v)",
+	// and the BETWEEN op takes two parameters, i.e. "%v %v %v AND %v". The SIZE op wraps the column with the db
+	// function using explicit argument indexes, i.e. "%[2]v(%[1]v) = %[3]v". A MySQL user may return "JSON_LENGTH".
+	// The SEARCH op defaults to the Postgres full-text search, i.e. "to_tsvector(%[1]v) %[2]v plainto_tsquery(%[3]v)".
+	// The HAS op checks the membership of the parameter in an array column, i.e. "%[3]v %[2]v(%[1]v)", and the
+	// CONTAINS op checks that an array column contains all elements of the array parameter, i.e. "%v %v %v".
+	// The INSUBNET op checks that an IP address column is contained in a CIDR subnet, using the Postgres inet operator
+	// "<<". A MySQL user may translate it to a range check over INET6_ATON.
+	// The IN and NIN ops get a parameter per element of the list, joined to a single argument, i.e. "%v %v (%v)"
+	// renders "age IN (?, ?)".
 	GetDBStatement func(Op, *FieldMeta) (string, string)
+	// TablePrefix is the table name that qualifies the emitted columns in the filter, sort and select expressions.
+	// For example, "users" renders "users.name = ?" instead of "name = ?". Nested fields are flattened using the
+	// FieldSep first, i.e. "users.address_name". Fields can override it using the "table" option in the struct tag,
+	// and columns that are already qualified (i.e. `column=addresses.city`) are left as is. It defaults to "".
+	TablePrefix string
+	// Schema is the database schema that is prepended to the table of table-qualified columns. For example, "analytics"
+	// with TablePrefix "events" renders "analytics.events.name = ?". Columns that are already qualified are left as is.
+	Schema string
+	// QuoteIdent if set quotes each segment of the emitted column identifiers. For example, using `DoubleQuote` renders
+	// "analytics"."events"."name" for the "analytics.events.name" column. It defaults to nil, and identifiers are not quoted.
+	QuoteIdent func(string) string
+	// Layouts is a registry of time layouts, referenced by fields using a "@"-prefixed name in the layout option.
+	// For example:
+	//
+	//	Layouts: map[string]string{"shortdate": "2006-01-02"}
+	//
+	//	type User struct {
+	//		CreatedAt time.Time `rql:"filter,layout=@shortdate"`
+	//	}
+	//
+	Layouts map[string]string
+	// TimeFn if set parses the values of time fields instead of their layouts, for formats that can not be expressed
+	// as a Go layout. For example, epoch milliseconds:
+	//
+	//	TimeFn: func(f *rql.FieldMeta, raw string) (time.Time, error) {
+	//		ms, err := strconv.ParseInt(raw, 10, 64)
+	//		if err != nil {
+	//			return time.Time{}, err
+	//		}
+	//		return time.UnixMilli(ms), nil
+	//	}
+	//
+	// It defaults to nil, and the values are parsed using the field layouts.
+	TimeFn func(f *FieldMeta, raw string) (time.Time, error)
+	// Ranges defines virtual range fields that are composed of a start and an end time fields, and accept only the
+	// `$overlaps` op. The op checks whether the range overlaps the requested one. For example:
+	//
+	//	Ranges: map[string][2]string{"period": {"starts_at", "ends_at"}}
+	//
+	//	{ "period": { "$overlaps": { "start": "2018-01-01T00:00:00Z", "end": "2018-02-01T00:00:00Z" } } }
+	//
+	// is translated to "(starts_at, ends_at) OVERLAPS (?, ?)". The fields are referenced by their names.
+	Ranges map[string][2]string
+	// ColumnExpr maps field names to SQL expressions that are rendered instead of their columns in the filter, sort,
+	// group and select expressions, i.e. for computed columns. Selected expressions are aliased with the field column.
+	// For example:
+	//
+	//	ColumnExpr: map[string]string{"full_name": "(first_name || ' ' || last_name)"}
+	//
+	//	{ "full_name": { "$like": "a8m%" } }
+	//
+	// is translated to "(first_name || ' ' || last_name) LIKE ?". The expressions are rendered as-is (they are not
+	// qualified or quoted), and must never be built from user input. Parenthesize them if their operators may bind
+	// differently than the column operators.
+	ColumnExpr map[string]string
+	// NotEqualOp is the db operator used for the `$neq` op by the default GetDBStatement. It defaults to "<>", but
+	// can be set to "!=". Note that in both cases, rows with a NULL value do not match the predicate. In order
+	// to match them as well, combine it with the `$null` op, i.e. { "$or": [{ "a": { "$neq": 1 } }, { "a": { "$null": true } }] }.
+	NotEqualOp string
+	// AndKeyword and OrKeyword are the logical connectors that are used for joining the predicates of the filter and
+	// the cursor expressions. They default to "AND" and "OR", and can be changed for non-SQL targets, i.e. "&&" and "||".
+	AndKeyword string
+	OrKeyword  string
+	// EscapeLike if true appends `ESCAPE '\'` to the `$like` and `$ilike` predicates, so that a backslash in the pattern
+	// matches the next character literally (i.e. "50\%" matches "50%"), and escapes the bare values that are translated
+	// to prefix matches by the DefaultStringOp. It defaults to false. Note that the `$startswith`, `$endswith` and
+	// `$includes` ops always escape their values.
+	EscapeLike bool
+	// ArrayScalarOp is the operator that is applied when a bare scalar is given for an array field, i.e. { "tags": "x" }.
+	// It defaults to the HAS op (membership), i.e. "? = ANY(tags)", since equality is rarely intended. It can be set to
+	// EQ in order to force equality, if it is supported by the field.
+	ArrayScalarOp Op
+	// DefaultStringOp is the operator that is applied when a bare value is given for a string field, i.e. { "name": "foo" }.
+	// It defaults to EQ, and can be set to LIKE or ILIKE for prefix matching. In this case, a wildcard is appended to the
+	// value, i.e. "name LIKE ?" with "foo%". It can be overridden per field using the "op" option in the tag, for example:
+	//
+	//	type User struct {
+	//		Email string `rql:"filter,op=eq"`
+	//	}
+	//
+	DefaultStringOp Op
 	// Lets the user define how a rql dir ('+','-') is translated to a db direction.
 	GetDBDir func(Direction) string
+	// Lets the user define how a rql null placement ("nullsfirst", "nullslast") is translated to the db syntax.
+	// It defaults to "NULLS FIRST" and "NULLS LAST". Dialects without native support may return an empty string.
+	GetDBNulls func(Nulls) string
+	// Lets the user define how a field collation (i.e. "en_US") is translated to the db syntax in the sort clause.
+	// It defaults to `COLLATE "en_US"`. A MySQL user may return "COLLATE utf8mb4_unicode_ci" without the quotes.
+	GetDBCollate func(string) string
 	// Sets the validator function based on the type
 	GetValidator func(f *FieldMeta) Validator
 	// Sets the convertor function based on the type
 	GetConverter func(f *FieldMeta) Converter
 	// Sets the supported operations for that type
 	GetSupportedOps func(f *FieldMeta) []Op
+	// ValueFn is an optional hook that transforms each converted operand of the filter (and the cursor) before it
+	// is added to the arguments. For example, lowercasing emails or mapping enum strings to their stored integers.
+	// The elements of list operands ($in, $nin and $contains) are transformed one by one. Returning an error
+	// aborts the parsing.
+	ValueFn func(f *FieldMeta, v interface{}) (interface{}, error)
 	// ParamSymbol is the placehold for parameters in the Filter expression the default is '?', postgres for example uses '$'
 	ParamSymbol string
 	// PositionalParams if true will append a numerical suffix to the ParamSymbol, i.e. ?1, ?2, etc.
 	PositionalParams bool
+	// ReuseParams if true reuses the same positional parameter for identical values, instead of creating a new
+	// placeholder and argument for each one of them. For example, "name = $1 OR nickname = $1" with the arguments
+	// ["a8m"]. It requires PositionalParams, and a driver that allows referencing a parameter more than once.
+	// It defaults to false.
+	ReuseParams bool
+	// NamedParams if true uses named parameters (i.e. :age_1, :name_2) in the filter expression, and populates
+	// the `FilterNamedArgs` map of the output. This is compatible with sqlx named queries. Columns that filtered
+	// more than once get distinct suffixes.
+	NamedParams bool
+	// NamedParamSymbol is the prefix of the named parameters in the filter expression. It defaults to ':' (sqlx),
+	// and can be set to '@' for pgx. In this case, `FilterNamedArgs` can be passed as is to `pgx.NamedArgs`:
+	//
+	//	rows, err := conn.Query(ctx, "SELECT * FROM users WHERE "+params.FilterExp, pgx.NamedArgs(params.FilterNamedArgs))
+	//
+	NamedParamSymbol string
 	// ParamOffset is the zero-based parameter offset added to positional parameters
 	// This allows the parameters to begin at another offeset and useful when the FilterExp falls after other arguments
 	// manually numbered in the SQL statement, the default is 1
 	ParamOffset int
+	// PaginationParams if true renders the limit and the offset as parameters in the `Params.SQL` output, instead of
+	// inlined integers, i.e. "LIMIT ? OFFSET ?" or "LIMIT $3 OFFSET $4". Their values follow the filter arguments
+	// in the `Params.SQLArgs` output. It defaults to false.
+	PaginationParams bool
+	// DebugComments if true populates the `Params.FilterDebug` field with a rendering of the filter expression,
+	// that annotates each predicate with a comment of its field and operator, i.e. "age > ? /* field: age op: gt */".
+	// It is useful for tracing the client conditions in the query logs, and should not be executed.
+	DebugComments bool
+	// MaxBodyBytes is the maximum size of a request body that is read by `Parser.ParseRequest`. Larger bodies
+	// are rejected with `ErrBodyTooLarge`. It defaults to 1MB.
+	MaxBodyBytes int64
+	// AllowNegativeUintBounds if true allows negative operands for the range comparisons ($gt, $gte, $lt and $lte)
+	// on unsigned integer fields, i.e. { "$gt": -1 }. By default, negative operands are rejected for all operators
+	// on unsigned integer fields (including the elements of $in and $between), with an error that wraps ErrNegativeUint.
+	AllowNegativeUintBounds bool
+	// MaxFilterFields is the maximum number of distinct columns that a filter can reference, in order to bound
+	// the join and index fan-out of a query. It counts distinct columns, not predicates. For example, the filter
+	// { "$or": [{ "age": 1 }, { "age": 2 }], "name": "a8m" } references 2 columns. It defaults to 0 (no limit).
+	MaxFilterFields int
+	// MaxFilterDepth is the maximum nesting level of the logical operators ($and, $or and $not) in a filter, in order
+	// to protect against deeply nested inputs. For example, { "$or": [{ "$and": [{ "age": 1 }] }] } is nested 2 levels
+	// deep. It defaults to 0 (no limit).
+	MaxFilterDepth int
+	// MaxFilterConditions is the maximum number of predicates (leaf conditions) in a filter, in order to bound the
+	// size of the generated query. For example, { "age": { "$gt": 1, "$lt": 10 }, "name": "a8m" } has 3 conditions.
+	// It defaults to 0 (no limit).
+	MaxFilterConditions int
+	// TrimKeys if true trims leading and trailing whitespace from the incoming filter, sort and select keys
+	// before resolving them, i.e. " name" is resolved as "name". It defaults to false.
+	TrimKeys bool
+	// ExpandSelect if true expands the "*" wildcard (or an empty select) to the columns of the fields that have
+	// the "select" option in the tag, instead of emitting a literal "*". It keeps the other (i.e. sensitive)
+	// columns excluded from the output. It defaults to false.
+	ExpandSelect bool
+	// DenySelectWildcard if true rejects the "*" wildcard in the select expression. It defaults to false.
+	DenySelectWildcard bool
+	// NormalizeKeyFn if set normalizes the field names and the incoming filter, sort and select keys, in order to match
+	// visually identical keys that were composed differently, i.e. "caf\u00e9" and "cafe\u0301". For example, using the
+	// Unicode normalization (NFC) of the golang.org/x/text/unicode/norm package:
+	//
+	//	NormalizeKeyFn: norm.NFC.String
+	//
+	// The keys of the ColumnExpr and the Ranges configs are normalized as well. Field names that collide after
+	// normalization are rejected by NewParser. It defaults to nil.
+	NormalizeKeyFn func(string) string
+	// Dialect is the SQL dialect used for rendering the placeholders and the pagination clause returned by `Params.SQL`.
+	// Setting it to `DialectOracle` uses colon-numbered placeholders (i.e. :1, :2) by default, and renders the
+	// pagination using the `OFFSET n ROWS FETCH NEXT m ROWS ONLY` syntax. It defaults to `LIMIT m OFFSET n`.
+	Dialect Dialect
 }
 
 // defaults sets the default configuration of Config.
@@ -196,10 +510,67 @@ func (c *Config) defaults() error {
 	if c.ColumnFn == nil {
 		c.ColumnFn = Column
 	}
+	defaultString(&c.NotEqualOp, opFormat[NEQ])
+	defaultString(&c.AndKeyword, opFormat[AND])
+	defaultString(&c.OrKeyword, opFormat[OR])
+	if c.ArrayScalarOp == "" {
+		c.ArrayScalarOp = HAS
+	}
+	if c.DefaultStringOp == "" {
+		c.DefaultStringOp = EQ
+	}
+	if !defaultStringOps[c.DefaultStringOp] {
+		return fmt.Errorf("rql: op %q is not supported as a default string op", c.DefaultStringOp)
+	}
+	if c.DefaultSortMerge != SortMergeReplace && c.DefaultSortMerge != SortMergeAppend {
+		return fmt.Errorf("rql: sort merge strategy %q is not supported", c.DefaultSortMerge)
+	}
+	if _, ok := nullsFormat[c.SortNulls]; c.SortNulls != "" && !ok {
+		return fmt.Errorf("rql: nulls placement %q is not supported", c.SortNulls)
+	}
 	if c.GetDBStatement == nil {
-		c.GetDBStatement = func(o Op, _ *FieldMeta) (string, string) {
-			if o == Op("any") {
+		neq, escape := c.NotEqualOp, c.EscapeLike
+		c.GetDBStatement = func(o Op, f *FieldMeta) (string, string) {
+			// columns of non-text fields are casted to text for pattern matching.
+			if f != nil && f.LikeCast && (o == LIKE || o == ILIKE) {
+				if escape {
+					return opFormat[o], `CAST(%[1]v AS TEXT) %[2]v %[3]v ESCAPE '\'`
+				}
+				return opFormat[o], "CAST(%[1]v AS TEXT) %[2]v %[3]v"
+			}
+			switch o {
+			case NEQ:
+				return neq, "%v %v %v"
+			case LIKE, ILIKE:
+				if escape {
+					return opFormat[o], `%v %v %v ESCAPE '\'`
+				}
+			case STARTSWITH, ENDSWITH, INCLUDES:
+				return opFormat[o], `%v %v %v ESCAPE '\'`
+			case Op("any"), IN, NIN:
 				return opFormat[o], "%v %v (%v)"
+			case NULL, NOTNULL:
+				return opFormat[o], "%v %v"
+			case BETWEEN:
+				return opFormat[o], "%v %v %v AND %v"
+			case SIZE:
+				return opFormat[o], "%[2]v(%[1]v) = %[3]v"
+			case SEARCH:
+				return opFormat[o], "to_tsvector(%[1]v) %[2]v plainto_tsquery(%[3]v)"
+			case HAS:
+				return opFormat[o], "%[3]v %[2]v(%[1]v)"
+			case OVERLAPS:
+				return opFormat[o], "(%[1]v) %[2]v (%[3]v, %[4]v)"
+			case JSONPATH:
+				// the extracted text is casted for comparing numbers and booleans.
+				format := "%[1]v%[2]v%[3]v"
+				if f.Cast != "" {
+					format = "(" + format + ")::" + f.Cast
+				}
+				if len(f.Path) > 1 {
+					return "#>>", format
+				}
+				return "->>", format
 			}
 			return opFormat[o], "%v %v %v"
 		}
@@ -209,6 +580,16 @@ func (c *Config) defaults() error {
 			return sortDirection[d]
 		}
 	}
+	if c.GetDBNulls == nil {
+		c.GetDBNulls = func(n Nulls) string {
+			return nullsFormat[n]
+		}
+	}
+	if c.GetDBCollate == nil {
+		c.GetDBCollate = func(collation string) string {
+			return "COLLATE " + DoubleQuote(collation)
+		}
+	}
 	if c.GetConverter == nil {
 		c.GetConverter = GetConverterFn
 	}
@@ -224,8 +605,16 @@ func (c *Config) defaults() error {
 	defaultString(&c.FieldSep, DefaultFieldSep)
 	defaultInt(&c.DefaultLimit, DefaultLimit)
 	defaultInt(&c.LimitMaxValue, DefaultMaxLimit)
+	if c.Dialect == DialectOracle && c.ParamSymbol == "" {
+		c.ParamSymbol = ":"
+		c.PositionalParams = true
+	}
 	defaultString(&c.ParamSymbol, DefaultParamSymbol)
+	defaultString(&c.NamedParamSymbol, DefaultNamedSymbol)
 	defaultInt(&c.ParamOffset, DefaultParamOffset)
+	if c.MaxBodyBytes == 0 {
+		c.MaxBodyBytes = DefaultMaxBodyBytes
+	}
 	return nil
 }
 
@@ -0,0 +1,182 @@
+package rql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ElasticQuery is the Elasticsearch translation of a query, returned by ParseElastic. It can be
+// encoded as is to the body of a search request. For example:
+//
+//	eq, err := parser.ParseElastic(b)
+//	if err != nil {
+//		return err
+//	}
+//	body, err := json.Marshal(eq)
+//	res, err := es.Search(es.Search.WithIndex("users"), es.Search.WithBody(bytes.NewReader(body)))
+type ElasticQuery struct {
+	// Query is the query DSL of the filter. For example: {"range": {"age": {"gt": 10}}}.
+	Query map[string]interface{} `json:"query"`
+	// Sort holds the sort clauses in their order. For example: [{"age": {"order": "desc"}}].
+	Sort []map[string]interface{} `json:"sort,omitempty"`
+	// Size is the maximum number of hits to return.
+	Size int `json:"size"`
+	// From is the number of hits to skip.
+	From int `json:"from"`
+}
+
+// elasticRanges maps the rql comparison operators to their Elasticsearch range parameters.
+var elasticRanges = map[Op]string{
+	LT:  "lt",
+	GT:  "gt",
+	LTE: "lte",
+	GTE: "gte",
+}
+
+// ParseElastic parses the given buffer into an Elasticsearch query. The filter, the sort and the pagination
+// are validated like in Parse, and the fields are referenced by their columns. The `$and`, `$or` and `$not`
+// operators are translated to the must, should and must_not clauses of a bool query, the comparisons to
+// range queries, `$like` and `$ilike` to wildcard queries, and `$search` to a match query. It returns an
+// error if the query uses a cursor, a group or an operator that has no equivalent (i.e. `$size`).
+func (p *Parser) ParseElastic(b []byte) (eq *ElasticQuery, err error) {
+	q := &Query{}
+	if err := q.UnmarshalJSON(b); err != nil {
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = perr
+			eq = nil
+		}
+	}()
+	expect(len(q.After) == 0, "cursor is not supported by elastic")
+	expect(len(q.Group) == 0, "group is not supported by elastic")
+	ps := p.newParseState()
+	pr := ps.query(q)
+	expect(pr.Limit > 0, "unlimited queries are not supported by elastic")
+	eq = &ElasticQuery{
+		Query: ps.elastic(ps.root),
+		Size:  pr.Limit,
+		From:  pr.Offset,
+	}
+	for _, sk := range ps.sortKeys {
+		order := "asc"
+		if sk.dir == DESC {
+			order = "desc"
+		}
+		eq.Sort = append(eq.Sort, map[string]interface{}{
+			p.fields[sk.name].Column: map[string]interface{}{"order": order},
+		})
+	}
+	parseStatePool.Put(ps)
+	return eq, nil
+}
+
+// elastic translates the given filter node to an Elasticsearch query.
+func (p *parseState) elastic(n *FilterNode) map[string]interface{} {
+	if n.Field != nil {
+		return p.elasticPredicate(n)
+	}
+	if n.Op == NOT {
+		return elasticBool("must_not", p.elastic(n.Children[0]))
+	}
+	switch len(n.Children) {
+	case 0:
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	case 1:
+		return p.elastic(n.Children[0])
+	}
+	queries := make([]interface{}, len(n.Children))
+	for i, c := range n.Children {
+		queries[i] = p.elastic(c)
+	}
+	if n.Op == OR {
+		return map[string]interface{}{
+			"bool": map[string]interface{}{"should": queries, "minimum_should_match": 1},
+		}
+	}
+	return elasticBool("must", queries...)
+}
+
+// elasticPredicate translates the given predicate to an Elasticsearch query.
+func (p *parseState) elasticPredicate(n *FilterNode) map[string]interface{} {
+	col := n.Field.Column
+	expect(n.Field.Path == nil, "JSON path field %q is not supported by elastic", n.Field.Name)
+	switch op := n.Op; op {
+	case EQ, HAS:
+		return elasticLeaf("term", col, n.Values[0])
+	case NEQ:
+		return elasticBool("must_not", elasticLeaf("term", col, n.Values[0]))
+	case IN:
+		return elasticLeaf("terms", col, n.Values[0])
+	case NIN:
+		return elasticBool("must_not", elasticLeaf("terms", col, n.Values[0]))
+	case LT, GT, LTE, GTE:
+		return elasticLeaf("range", col, map[string]interface{}{elasticRanges[op]: n.Values[0]})
+	case BETWEEN:
+		return elasticLeaf("range", col, map[string]interface{}{"gte": n.Values[0], "lte": n.Values[1]})
+	case LIKE, ILIKE, STARTSWITH, ENDSWITH, INCLUDES:
+		return elasticLeaf("wildcard", col, map[string]interface{}{
+			"value":            likeWildcard(fmt.Sprint(n.Values[0])),
+			"case_insensitive": op == ILIKE,
+		})
+	case SEARCH:
+		return elasticLeaf("match", col, n.Values[0])
+	case NULL:
+		return elasticBool("must_not", map[string]interface{}{"exists": map[string]interface{}{"field": col}})
+	case NOTNULL:
+		return map[string]interface{}{"exists": map[string]interface{}{"field": col}}
+	case CONTAINS:
+		values, _ := n.Values[0].([]interface{})
+		queries := make([]interface{}, len(values))
+		for i, v := range values {
+			queries[i] = elasticLeaf("term", col, v)
+		}
+		return elasticBool("must", queries...)
+	default:
+		expectField(false, ErrInvalidOp, n.Field.Name, nil, "op %q on field %q is not supported by elastic", p.op(op), n.Field.Name)
+		return nil
+	}
+}
+
+// elasticLeaf returns a leaf query of the given type. for example: {"term": {"name": "a8m"}}.
+func elasticLeaf(typ, col string, v interface{}) map[string]interface{} {
+	return map[string]interface{}{typ: map[string]interface{}{col: v}}
+}
+
+// elasticBool returns a bool query with the given occurrence type. for example: {"bool": {"must": [...]}}.
+func elasticBool(occur string, queries ...interface{}) map[string]interface{} {
+	return map[string]interface{}{"bool": map[string]interface{}{occur: queries}}
+}
+
+// likeWildcard translates the given LIKE pattern to a wildcard pattern.
+// for example: "a8m_%" is translated to "a8m?*".
+func likeWildcard(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '%':
+			b.WriteByte('*')
+		case c == '_':
+			b.WriteByte('?')
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(escapeWildcard(pattern[i]))
+		default:
+			b.WriteString(escapeWildcard(c))
+		}
+	}
+	return b.String()
+}
+
+// escapeWildcard escapes the special characters of wildcard patterns.
+func escapeWildcard(c byte) string {
+	if c == '*' || c == '?' || c == '\\' {
+		return `\` + string(c)
+	}
+	return string(c)
+}
@@ -0,0 +1,114 @@
+package rql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseElastic(t *testing.T) {
+	type M = map[string]interface{}
+	model := new(struct {
+		ID        int      `rql:"filter,sort,name=id,column=_id"`
+		Age       int      `rql:"filter,sort"`
+		Name      string   `rql:"filter,sort,name=full_name,column=name"`
+		Bio       string   `rql:"filter,search"`
+		Tags      []string `rql:"filter"`
+		DeletedAt *string  `rql:"filter"`
+	})
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr bool
+		wantOut *ElasticQuery
+	}{
+		{
+			name:  "empty query",
+			input: []byte(`{}`),
+			wantOut: &ElasticQuery{
+				Query: M{"match_all": M{}},
+				Size:  25,
+			},
+		},
+		{
+			name: "comparisons",
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": { "$gt": 10 } },
+						{ "age": { "$between": [1, 5] } },
+						{ "id": { "$nin": [1, 2] } },
+						{ "full_name": "a8m" }
+					]
+				},
+				"sort": ["-age", "full_name"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantOut: &ElasticQuery{
+				Query: M{"bool": M{
+					"should": []interface{}{
+						M{"range": M{"age": M{"gt": 10}}},
+						M{"range": M{"age": M{"gte": 1, "lte": 5}}},
+						M{"bool": M{"must_not": []interface{}{M{"terms": M{"_id": []interface{}{1, 2}}}}}},
+						M{"term": M{"name": "a8m"}},
+					},
+					"minimum_should_match": 1,
+				}},
+				Sort: []map[string]interface{}{{"age": M{"order": "desc"}}, {"name": M{"order": "asc"}}},
+				Size: 10,
+				From: 20,
+			},
+		},
+		{
+			name: "like, search, not and null",
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "full_name": { "$ilike": "a*8_%" } },
+						{ "bio": { "$search": "gopher" } },
+						{ "$not": { "deleted_at": { "$null": false } } },
+						{ "tags": { "$contains": ["go"] } }
+					]
+				}
+			}`),
+			wantOut: &ElasticQuery{
+				Query: M{"bool": M{"must": []interface{}{
+					M{"wildcard": M{"name": M{"value": `a\*8?*`, "case_insensitive": true}}},
+					M{"match": M{"bio": "gopher"}},
+					M{"bool": M{"must_not": []interface{}{M{"exists": M{"field": "deleted_at"}}}}},
+					M{"bool": M{"must": []interface{}{M{"term": M{"tags": "go"}}}}},
+				}}},
+				Size: 25,
+			},
+		},
+		{
+			name: "unsupported op",
+			input: []byte(`{
+				"filter": { "tags": { "$size": 2 } }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "invalid value",
+			input: []byte(`{
+				"filter": { "age": "a8m" }
+			}`),
+			wantErr: true,
+		},
+	}
+	p, err := NewParser(Config{Model: model, Log: t.Logf})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.ParseElastic(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			if !reflect.DeepEqual(out, tt.wantOut) {
+				t.Fatalf("elastic query:\n\tgot: %#v\n\twant %#v", out, tt.wantOut)
+			}
+		})
+	}
+}
@@ -56,7 +56,7 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	err = db.Where(p.FilterExp, p.FilterArgs).
+	err = db.Where(p.FilterExp, p.FilterArgs...).
 		Offset(p.Offset).
 		Limit(p.Limit).
 		Order(p.Sort).
@@ -0,0 +1,97 @@
+package rql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explain returns a human-readable description of the parsed query, i.e. for debugging what a complex
+// query translates to. It renders the filter tree (see FilterNode) with the resolved field names and the
+// converted values, followed by the resolved sort, select, group and pagination. Groups of a single child
+// are collapsed, and empty parts are omitted. For example:
+//
+//	filter:
+//	  AND
+//	  ├── OR
+//	  │   ├── age GT 10
+//	  │   └── name IN ["a8m", "noam"]
+//	  └── deleted_at NULL
+//	sort: age desc, id
+//	limit: 25
+//	offset: 0
+func (p *Params) Explain() string {
+	var b strings.Builder
+	if p.Filter != nil && p.Filter.hasPredicate() {
+		b.WriteString("filter:\n")
+		explainNode(&b, p.Filter, "  ", "  ")
+	}
+	for _, part := range []struct{ name, value string }{
+		{"cursor", p.CursorExp},
+		{"sort", p.Sort},
+		{"select", p.Select},
+		{"group", p.Group},
+	} {
+		if part.value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", part.name, part.value)
+		}
+	}
+	if p.Distinct {
+		b.WriteString("distinct: true\n")
+	}
+	if p.Limit > 0 {
+		fmt.Fprintf(&b, "limit: %d\n", p.Limit)
+	}
+	fmt.Fprintf(&b, "offset: %d\n", p.Offset)
+	return b.String()
+}
+
+// explainNode writes the given node with the given prefix, and its children below it, indented with
+// the given indentation. Groups of a single child (except NOT) are collapsed to their child.
+func explainNode(b *strings.Builder, n *FilterNode, prefix, indent string) {
+	for !n.IsPredicate() && n.Op != NOT && len(n.Children) == 1 {
+		n = n.Children[0]
+	}
+	b.WriteString(prefix)
+	if n.IsPredicate() {
+		b.WriteString(n.Field.Name)
+		b.WriteByte(' ')
+		b.WriteString(strings.ToUpper(string(n.Op)))
+		for i, v := range n.Values {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteByte(' ')
+			explainValue(b, v)
+		}
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteString(strings.ToUpper(string(n.Op)))
+	b.WriteByte('\n')
+	for i, c := range n.Children {
+		if i == len(n.Children)-1 {
+			explainNode(b, c, indent+"└── ", indent+"    ")
+		} else {
+			explainNode(b, c, indent+"├── ", indent+"│   ")
+		}
+	}
+}
+
+// explainValue writes the given predicate value. Strings are quoted, and lists are written in brackets.
+func explainValue(b *strings.Builder, v interface{}) {
+	switch v := v.(type) {
+	case string:
+		fmt.Fprintf(b, "%q", v)
+	case []interface{}:
+		b.WriteByte('[')
+		for i := range v {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			explainValue(b, v[i])
+		}
+		b.WriteByte(']')
+	default:
+		fmt.Fprintf(b, "%v", v)
+	}
+}
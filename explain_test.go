@@ -0,0 +1,77 @@
+package rql
+
+import (
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	model := new(struct {
+		ID        int     `rql:"filter,sort"`
+		Age       int     `rql:"filter,sort"`
+		Name      string  `rql:"filter,sort"`
+		DeletedAt *string `rql:"filter"`
+	})
+	tests := []struct {
+		name  string
+		conf  Config
+		input []byte
+		want  string
+	}{
+		{
+			name:  "empty query",
+			conf:  Config{Model: model},
+			input: []byte(`{}`),
+			want:  "limit: 25\noffset: 0\n",
+		},
+		{
+			name: "representative query",
+			conf: Config{Model: model, SortTiebreaker: []string{"id"}},
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{
+							"$or": [
+								{ "age": { "$gt": 10 } },
+								{ "name": { "$in": ["a8m", "noam"] } }
+							]
+						},
+						{ "$not": { "id": { "$between": [1, 5] } } }
+					]
+				},
+				"select": ["name"],
+				"sort": ["-age"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			want: "filter:\n" +
+				"  AND\n" +
+				"  ├── OR\n" +
+				"  │   ├── age GT 10\n" +
+				"  │   └── name IN [\"a8m\", \"noam\"]\n" +
+				"  └── NOT\n" +
+				"      └── id BETWEEN 1, 5\n" +
+				"sort: age desc, id\n" +
+				"select: name\n" +
+				"limit: 10\n" +
+				"offset: 20\n",
+		},
+		{
+			name:  "single predicate",
+			conf:  Config{Model: model, AllowUnlimited: true},
+			input: []byte(`{"filter": {"deleted_at": {"$null": true}}}`),
+			want:  "filter:\n  deleted_at NULL\noffset: 0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := MustNewParser(tt.conf)
+			out, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := out.Explain(); got != tt.want {
+				t.Fatalf("explain:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
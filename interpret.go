@@ -0,0 +1,104 @@
+package rql
+
+// Interpretation describes how a query was interpreted by the parser, i.e. for echoing the normalized
+// query back to the client alongside the results. It is JSON-serializable. For example:
+//
+//	{
+//		"filter": {
+//			"op": "$or",
+//			"children": [
+//				{ "field": "age", "op": "$gt", "values": [10] },
+//				{ "field": "name", "op": "$eq", "values": ["a8m"] }
+//			]
+//		},
+//		"sort": [{ "field": "age", "desc": true }],
+//		"limit": 25,
+//		"offset": 0
+//	}
+type Interpretation struct {
+	// Filter is the interpreted filter tree. It is nil if the query has no conditions.
+	Filter *InterpretedFilter `json:"filter,omitempty"`
+	// Sort holds the resolved sort fields, including the DefaultSort and the SortTiebreaker fields.
+	Sort []InterpretedSort `json:"sort,omitempty"`
+	// Limit and Offset are the resolved pagination values. A zero limit means no limit.
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// InterpretedFilter is a node in the interpreted filter tree. A node is either a logical group ($and, $or
+// or $not) of child nodes, or a predicate that applies its operator on a field. Groups of a single child are
+// collapsed to their child.
+type InterpretedFilter struct {
+	// Field is the name of the field of a predicate, and empty for logical groups.
+	Field string `json:"field,omitempty"`
+	// Op is the prefixed operator of the node. i.e. "$or" or "$gt".
+	Op string `json:"op"`
+	// Values holds the converted operands of a predicate. For example, one value for "$eq" (or a slice
+	// for "$in"), and two values for "$between".
+	Values []interface{} `json:"values,omitempty"`
+	// Children holds the nodes of a logical group.
+	Children []*InterpretedFilter `json:"children,omitempty"`
+}
+
+// InterpretedSort is a resolved field of the sort clause.
+type InterpretedSort struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// Interpret parses the given buffer like Parse, and returns the interpretation of the query along with
+// its Params.
+func (p *Parser) Interpret(b []byte) (it *Interpretation, pr *Params, err error) {
+	q := &Query{}
+	if err := q.UnmarshalJSON(b); err != nil {
+		return nil, nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = perr
+			it, pr = nil, nil
+		}
+	}()
+	ps := p.newParseState()
+	pr = ps.query(q)
+	it = &Interpretation{
+		Limit:  pr.Limit,
+		Offset: pr.Offset,
+	}
+	if pr.Filter.hasPredicate() {
+		it.Filter = ps.interpret(pr.Filter)
+	}
+	for _, sk := range ps.sortKeys {
+		it.Sort = append(it.Sort, InterpretedSort{Field: sk.name, Desc: sk.dir == DESC})
+	}
+	parseStatePool.Put(ps)
+	return it, pr, nil
+}
+
+// interpret translates the given filter node to an interpreted filter.
+func (p *parseState) interpret(n *FilterNode) *InterpretedFilter {
+	switch {
+	case n.IsPredicate():
+		f := &InterpretedFilter{Field: n.Field.Name, Op: p.op(n.Op), Values: n.Values}
+		// null predicates are described the way they are written, i.e. { "$null": false }.
+		switch n.Op {
+		case NULL:
+			f.Values = []interface{}{true}
+		case NOTNULL:
+			f.Op, f.Values = p.op(NULL), []interface{}{false}
+		}
+		return f
+	case n.Op != NOT && len(n.Children) == 1:
+		return p.interpret(n.Children[0])
+	default:
+		f := &InterpretedFilter{Op: p.op(n.Op), Children: make([]*InterpretedFilter, len(n.Children))}
+		for i, c := range n.Children {
+			f.Children[i] = p.interpret(c)
+		}
+		return f
+	}
+}
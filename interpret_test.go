@@ -0,0 +1,117 @@
+package rql
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInterpret(t *testing.T) {
+	model := new(struct {
+		ID        int        `rql:"filter,sort"`
+		Age       int        `rql:"filter,sort"`
+		Name      string     `rql:"filter,sort"`
+		CreatedAt time.Time  `rql:"filter,sort"`
+		DeletedAt *time.Time `rql:"filter"`
+	})
+	tests := []struct {
+		name     string
+		input    []byte
+		wantErr  bool
+		wantOut  *Interpretation
+		wantJSON string
+	}{
+		{
+			name:     "empty query",
+			input:    []byte(`{}`),
+			wantOut:  &Interpretation{Limit: 25},
+			wantJSON: `{"limit":25,"offset":0}`,
+		},
+		{
+			name: "representative query",
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{
+							"$or": [
+								{ "age": { "$gt": 10 } },
+								{ "name": { "$in": ["a8m", "noam"] } }
+							]
+						},
+						{ "$not": { "id": { "$between": [1, 5] } } },
+						{ "deleted_at": { "$null": false } },
+						{ "created_at": { "$lt": "2020-01-02T00:00:00Z" } }
+					]
+				},
+				"sort": ["-age"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantOut: &Interpretation{
+				Filter: &InterpretedFilter{
+					Op: "$and",
+					Children: []*InterpretedFilter{
+						{
+							Op: "$or",
+							Children: []*InterpretedFilter{
+								{Field: "age", Op: "$gt", Values: []interface{}{10}},
+								{Field: "name", Op: "$in", Values: []interface{}{[]interface{}{"a8m", "noam"}}},
+							},
+						},
+						{
+							Op:       "$not",
+							Children: []*InterpretedFilter{{Field: "id", Op: "$between", Values: []interface{}{1, 5}}},
+						},
+						{Field: "deleted_at", Op: "$null", Values: []interface{}{false}},
+						{Field: "created_at", Op: "$lt", Values: []interface{}{time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}},
+					},
+				},
+				Sort:   []InterpretedSort{{Field: "age", Desc: true}, {Field: "id"}},
+				Limit:  10,
+				Offset: 20,
+			},
+			wantJSON: `{"filter":{"op":"$and","children":[` +
+				`{"op":"$or","children":[{"field":"age","op":"$gt","values":[10]},{"field":"name","op":"$in","values":[["a8m","noam"]]}]},` +
+				`{"op":"$not","children":[{"field":"id","op":"$between","values":[1,5]}]},` +
+				`{"field":"deleted_at","op":"$null","values":[false]},` +
+				`{"field":"created_at","op":"$lt","values":["2020-01-02T00:00:00Z"]}]},` +
+				`"sort":[{"field":"age","desc":true},{"field":"id","desc":false}],"limit":10,"offset":20}`,
+		},
+		{
+			name: "invalid query",
+			input: []byte(`{
+				"filter": { "unknown": 1 }
+			}`),
+			wantErr: true,
+		},
+	}
+	p, err := NewParser(Config{Model: model, Log: t.Logf, SortTiebreaker: []string{"id"}})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, params, err := p.Interpret(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if params == nil {
+				t.Fatal("expected params to be returned")
+			}
+			if !reflect.DeepEqual(out, tt.wantOut) {
+				t.Fatalf("interpretation:\n\tgot: %#v\n\twant %#v", out, tt.wantOut)
+			}
+			b, err := json.Marshal(out)
+			if err != nil {
+				t.Fatalf("failed to marshal interpretation: %v", err)
+			}
+			if string(b) != tt.wantJSON {
+				t.Fatalf("interpretation json:\n\tgot: %s\n\twant %s", b, tt.wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package rql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MongoQuery is the MongoDB translation of a query, returned by ParseMongo. Its Filter can be
+// converted to bson.M, and its Sort to bson.D. For example:
+//
+//	mq, err := parser.ParseMongo(b)
+//	if err != nil {
+//		return err
+//	}
+//	sort := bson.D{}
+//	for _, s := range mq.Sort {
+//		sort = append(sort, bson.E{Key: s.Key, Value: s.Value})
+//	}
+//	opts := options.Find().SetSort(sort).SetLimit(int64(mq.Limit)).SetSkip(int64(mq.Skip))
+//	cur, err := coll.Find(ctx, bson.M(mq.Filter), opts)
+type MongoQuery struct {
+	// Filter is the query document. For example: {"age": {"$gt": 10}}.
+	Filter map[string]interface{}
+	// Sort holds the sort keys in their order.
+	Sort []MongoSort
+	// Limit is the maximum number of documents to return.
+	Limit int
+	// Skip is the number of documents to skip.
+	Skip int
+}
+
+// MongoSort is a sort key of a MongoQuery. Its value is 1 for ascending order, and -1 for descending order.
+type MongoSort struct {
+	Key   string
+	Value int
+}
+
+// mongoOps maps the rql operators to their MongoDB equivalents.
+var mongoOps = map[Op]string{
+	EQ:       "$eq",
+	NEQ:      "$ne",
+	LT:       "$lt",
+	GT:       "$gt",
+	LTE:      "$lte",
+	GTE:      "$gte",
+	IN:       "$in",
+	NIN:      "$nin",
+	SIZE:     "$size",
+	HAS:      "$eq",
+	CONTAINS: "$all",
+}
+
+// ParseMongo parses the given buffer into a MongoDB query. The filter, the sort and the pagination
+// are validated like in Parse, and the fields are referenced by their columns. The `$like` and `$ilike`
+// operators are translated to anchored `$regex` patterns, `$not` to `$nor`, and `$search` to `$text`.
+// It returns an error if the query uses a cursor, a group or a custom operator.
+func (p *Parser) ParseMongo(b []byte) (mq *MongoQuery, err error) {
+	q := &Query{}
+	if err := q.UnmarshalJSON(b); err != nil {
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = perr
+			mq = nil
+		}
+	}()
+	expect(len(q.After) == 0, "cursor is not supported by mongo")
+	expect(len(q.Group) == 0, "group is not supported by mongo")
+	ps := p.newParseState()
+	pr := ps.query(q)
+	mq = &MongoQuery{
+		Filter: ps.mongo(ps.root),
+		Sort:   make([]MongoSort, 0, len(ps.sortKeys)),
+		Limit:  pr.Limit,
+		Skip:   pr.Offset,
+	}
+	for _, sk := range ps.sortKeys {
+		s := MongoSort{Key: p.fields[sk.name].Column, Value: 1}
+		if sk.dir == DESC {
+			s.Value = -1
+		}
+		mq.Sort = append(mq.Sort, s)
+	}
+	parseStatePool.Put(ps)
+	return mq, nil
+}
+
+// mongo translates the given filter node to a MongoDB query document.
+func (p *parseState) mongo(n *FilterNode) map[string]interface{} {
+	if n.Field != nil {
+		return p.mongoPredicate(n)
+	}
+	if n.Op == NOT {
+		return map[string]interface{}{"$nor": []interface{}{p.mongo(n.Children[0])}}
+	}
+	if len(n.Children) == 0 {
+		return map[string]interface{}{}
+	}
+	if len(n.Children) == 1 {
+		return p.mongo(n.Children[0])
+	}
+	docs := make([]interface{}, len(n.Children))
+	for i, c := range n.Children {
+		docs[i] = p.mongo(c)
+	}
+	return map[string]interface{}{"$" + strings.ToLower(string(n.Op)): docs}
+}
+
+// mongoPredicate translates the given predicate to a MongoDB query document.
+func (p *parseState) mongoPredicate(n *FilterNode) map[string]interface{} {
+	var cond map[string]interface{}
+	switch op := n.Op; op {
+	case SEARCH:
+		return map[string]interface{}{"$text": map[string]interface{}{"$search": n.Values[0]}}
+	case NULL:
+		cond = map[string]interface{}{"$eq": nil}
+	case NOTNULL:
+		cond = map[string]interface{}{"$ne": nil}
+	case BETWEEN:
+		cond = map[string]interface{}{"$gte": n.Values[0], "$lte": n.Values[1]}
+	case LIKE, ILIKE, STARTSWITH, ENDSWITH, INCLUDES:
+		cond = map[string]interface{}{"$regex": likeRegexp(fmt.Sprint(n.Values[0]))}
+		if op == ILIKE {
+			cond["$options"] = "i"
+		}
+	default:
+		mop, ok := mongoOps[op]
+		expectField(ok, ErrInvalidOp, n.Field.Name, nil, "op %q on field %q is not supported by mongo", p.op(op), n.Field.Name)
+		cond = map[string]interface{}{mop: n.Values[0]}
+	}
+	// JSON paths are addressed using the dot notation, i.e. "scores.0".
+	key := n.Field.Column
+	if n.Field.Path != nil {
+		key += "." + strings.Join(n.Field.Path, ".")
+	}
+	return map[string]interface{}{key: cond}
+}
+
+// likeRegexp translates the given LIKE pattern to an anchored regular expression.
+// for example: "a8m%" is translated to "^a8m.*$".
+func likeRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteByte('^')
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '%':
+			b.WriteString(".*")
+		case c == '_':
+			b.WriteByte('.')
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteByte('$')
+	return b.String()
+}
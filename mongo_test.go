@@ -0,0 +1,137 @@
+package rql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMongo(t *testing.T) {
+	type M = map[string]interface{}
+	model := new(struct {
+		ID        int      `rql:"filter,sort,name=id,column=_id"`
+		Age       int      `rql:"filter,sort"`
+		Name      string   `rql:"filter,sort,name=full_name,column=name"`
+		Tags      []string `rql:"filter"`
+		DeletedAt *string  `rql:"filter"`
+		Scores    []int    `rql:"filter,json"`
+	})
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr bool
+		wantOut *MongoQuery
+	}{
+		{
+			name:  "empty query",
+			input: []byte(`{}`),
+			wantOut: &MongoQuery{
+				Filter: M{},
+				Sort:   []MongoSort{},
+				Limit:  25,
+			},
+		},
+		{
+			name: "comparisons",
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": { "$gt": 10 } },
+						{ "age": { "$between": [1, 5] } },
+						{ "id": { "$in": [1, 2] } },
+						{ "full_name": "a8m" }
+					]
+				},
+				"sort": ["-age", "full_name"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantOut: &MongoQuery{
+				Filter: M{"$or": []interface{}{
+					M{"age": M{"$gt": 10}},
+					M{"age": M{"$gte": 1, "$lte": 5}},
+					M{"_id": M{"$in": []interface{}{1, 2}}},
+					M{"name": M{"$eq": "a8m"}},
+				}},
+				Sort:  []MongoSort{{Key: "age", Value: -1}, {Key: "name", Value: 1}},
+				Limit: 10,
+				Skip:  20,
+			},
+		},
+		{
+			name: "like, not and null",
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "full_name": { "$like": "a.8%" } },
+						{ "full_name": { "$ilike": "_m\\%" } },
+						{ "$not": { "deleted_at": { "$null": true } } },
+						{ "tags": { "$contains": ["go"] } },
+						{ "scores.0": { "$gte": 90 } }
+					]
+				}
+			}`),
+			wantOut: &MongoQuery{
+				Filter: M{"$and": []interface{}{
+					M{"name": M{"$regex": `^a\.8.*$`}},
+					M{"name": M{"$regex": `^.m%$`, "$options": "i"}},
+					M{"$nor": []interface{}{M{"deleted_at": M{"$eq": nil}}}},
+					M{"tags": M{"$all": []interface{}{"go"}}},
+					M{"scores.0": M{"$gte": 90.0}},
+				}},
+				Sort:  []MongoSort{},
+				Limit: 25,
+			},
+		},
+		{
+			name: "escaped string ops",
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "full_name": { "$startswith": "a%" } },
+						{ "full_name": { "$endswith": "m." } },
+						{ "full_name": { "$includes": "_8" } }
+					]
+				}
+			}`),
+			wantOut: &MongoQuery{
+				Filter: M{"$or": []interface{}{
+					M{"name": M{"$regex": `^a%.*$`}},
+					M{"name": M{"$regex": `^.*m\.$`}},
+					M{"name": M{"$regex": `^.*_8.*$`}},
+				}},
+				Sort:  []MongoSort{},
+				Limit: 25,
+			},
+		},
+		{
+			name: "invalid value",
+			input: []byte(`{
+				"filter": { "age": "a8m" }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "cursor",
+			input: []byte(`{
+				"sort": ["id"],
+				"after": { "id": 1 }
+			}`),
+			wantErr: true,
+		},
+	}
+	p, err := NewParser(Config{Model: model, Log: t.Logf})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.ParseMongo(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			if !reflect.DeepEqual(out, tt.wantOut) {
+				t.Fatalf("mongo query:\n\tgot: %#v\n\twant %#v", out, tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package rql
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RediSearchQuery is the RediSearch translation of a query, returned by ParseRediSearch. Its fields are
+// the arguments of the FT.SEARCH command. For example:
+//
+//	rq, err := parser.ParseRediSearch(b)
+//	if err != nil {
+//		return err
+//	}
+//	args := []interface{}{"FT.SEARCH", "users", rq.Query, "LIMIT", rq.Offset, rq.Num}
+//	if rq.SortBy != "" {
+//		args = append(args, "SORTBY", rq.SortBy, rq.SortOrder)
+//	}
+//	res, err := rdb.Do(ctx, args...).Result()
+type RediSearchQuery struct {
+	// Query is the query string of the filter. For example: "@age:[(10 +inf] @name:a8m".
+	Query string
+	// SortBy is the field of the SORTBY argument. It is empty if the query has no sort.
+	SortBy string
+	// SortOrder is the order of the SORTBY argument. i.e. "ASC" or "DESC".
+	SortOrder string
+	// Offset is the number of results to skip.
+	Offset int
+	// Num is the maximum number of results to return.
+	Num int
+}
+
+// ParseRediSearch parses the given buffer into a RediSearch query. The filter, the sort and the pagination
+// are validated like in Parse, and the fields are referenced by their columns. Numeric fields (and time fields,
+// as unix timestamps) are translated to range queries, arrays and booleans to tag queries, and strings to text
+// queries. `$like` and `$ilike` are translated to prefix or wildcard matching, `$or` to `|` and `$not` to `-`.
+// It returns an error if the query uses a cursor, a group, more than one sort field, or an operator that has no
+// equivalent (i.e. `$size`).
+func (p *Parser) ParseRediSearch(b []byte) (rq *RediSearchQuery, err error) {
+	q := &Query{}
+	if err := q.UnmarshalJSON(b); err != nil {
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = perr
+			rq = nil
+		}
+	}()
+	expect(len(q.After) == 0, "cursor is not supported by redisearch")
+	expect(len(q.Group) == 0, "group is not supported by redisearch")
+	ps := p.newParseState()
+	pr := ps.query(q)
+	expect(pr.Limit > 0, "unlimited queries are not supported by redisearch")
+	expect(len(ps.sortKeys) <= 1, "redisearch supports sorting by one field only")
+	rq = &RediSearchQuery{
+		Query:  ps.redis(ps.root, true),
+		Offset: pr.Offset,
+		Num:    pr.Limit,
+	}
+	if len(ps.sortKeys) == 1 {
+		sk := ps.sortKeys[0]
+		rq.SortBy, rq.SortOrder = p.fields[sk.name].Column, "ASC"
+		if sk.dir == DESC {
+			rq.SortOrder = "DESC"
+		}
+	}
+	parseStatePool.Put(ps)
+	return rq, nil
+}
+
+// redis translates the given filter node to a RediSearch query. Groups are parenthesized, unless
+// they are the top-level node.
+func (p *parseState) redis(n *FilterNode, top bool) string {
+	if n.Field != nil {
+		return p.redisPredicate(n)
+	}
+	if n.Op == NOT {
+		return "-" + p.redis(n.Children[0], false)
+	}
+	switch len(n.Children) {
+	case 0:
+		return "*"
+	case 1:
+		return p.redis(n.Children[0], top)
+	}
+	queries := make([]string, len(n.Children))
+	for i, c := range n.Children {
+		queries[i] = p.redis(c, false)
+	}
+	sep := " "
+	if n.Op == OR {
+		sep = "|"
+	}
+	if top {
+		return strings.Join(queries, sep)
+	}
+	return "(" + strings.Join(queries, sep) + ")"
+}
+
+// redisPredicate translates the given predicate to a RediSearch query.
+func (p *parseState) redisPredicate(n *FilterNode) string {
+	expect(n.Field.Path == nil, "JSON path field %q is not supported by redisearch", n.Field.Name)
+	field := "@" + n.Field.Column + ":"
+	switch op := n.Op; op {
+	case EQ:
+		return field + redisTerm(n.Values[0])
+	case HAS:
+		return field + "{" + redisEscape(redisString(n.Values[0])) + "}"
+	case NEQ:
+		return "-" + field + redisTerm(n.Values[0])
+	case IN, NIN:
+		values, _ := n.Values[0].([]interface{})
+		terms := make([]string, len(values))
+		for i, v := range values {
+			terms[i] = field + redisTerm(v)
+		}
+		q := "(" + strings.Join(terms, "|") + ")"
+		if op == NIN {
+			q = "-" + q
+		}
+		return q
+	case LT, GT, LTE, GTE, BETWEEN:
+		bounds := make([]string, len(n.Values))
+		for i, v := range n.Values {
+			num, ok := redisNumber(v)
+			expectField(ok, ErrInvalidOp, n.Field.Name, nil, "op %q on field %q is supported by redisearch only for numbers", p.op(op), n.Field.Name)
+			bounds[i] = num
+		}
+		switch op {
+		case LT:
+			return field + "[-inf (" + bounds[0] + "]"
+		case LTE:
+			return field + "[-inf " + bounds[0] + "]"
+		case GT:
+			return field + "[(" + bounds[0] + " +inf]"
+		case GTE:
+			return field + "[" + bounds[0] + " +inf]"
+		default:
+			return field + "[" + bounds[0] + " " + bounds[1] + "]"
+		}
+	case LIKE, ILIKE, STARTSWITH, ENDSWITH, INCLUDES:
+		pattern := n.Values[0].(string)
+		// trailing wildcards are prefix matches, and other patterns are translated to wildcard matches.
+		if prefix := strings.TrimSuffix(pattern, "%"); prefix != pattern && !strings.ContainsAny(prefix, `%_\`) {
+			return field + redisEscape(prefix) + "*"
+		}
+		return field + "w'" + strings.Replace(likeWildcard(pattern), "'", `\'`, -1) + "'"
+	case SEARCH:
+		words := strings.Fields(n.Values[0].(string))
+		for i := range words {
+			words[i] = redisEscape(words[i])
+		}
+		return field + "(" + strings.Join(words, " ") + ")"
+	case CONTAINS:
+		values, _ := n.Values[0].([]interface{})
+		terms := make([]string, len(values))
+		for i, v := range values {
+			terms[i] = field + "{" + redisEscape(redisString(v)) + "}"
+		}
+		return "(" + strings.Join(terms, " ") + ")"
+	case NULL:
+		return "ismissing(@" + n.Field.Column + ")"
+	case NOTNULL:
+		return "-ismissing(@" + n.Field.Column + ")"
+	default:
+		expectField(false, ErrInvalidOp, n.Field.Name, nil, "op %q on field %q is not supported by redisearch", p.op(op), n.Field.Name)
+		return ""
+	}
+}
+
+// redisTerm returns the equality term of the given value. i.e. a single-value range for numbers,
+// a tag for booleans, or an escaped text otherwise.
+func redisTerm(v interface{}) string {
+	if num, ok := redisNumber(v); ok {
+		return "[" + num + " " + num + "]"
+	}
+	if _, ok := v.(bool); ok {
+		return "{" + redisString(v) + "}"
+	}
+	return redisEscape(redisString(v))
+}
+
+// redisNumber formats the given value as a RediSearch number. Times are formatted as unix timestamps.
+func redisNumber(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case time.Time:
+		return strconv.FormatInt(v.Unix(), 10), true
+	default:
+		return "", false
+	}
+}
+
+// redisString formats the given scalar value as a string.
+func redisString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		num, _ := redisNumber(v)
+		return num
+	}
+}
+
+// redisEscape escapes the punctuation and the whitespace characters of the given text,
+// that are special characters in the RediSearch syntax.
+func redisEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(",.<>{}[]\"':;!@#$%^&*()-+=~|/\\? \t", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
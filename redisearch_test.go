@@ -0,0 +1,140 @@
+package rql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRediSearch(t *testing.T) {
+	model := new(struct {
+		ID        int       `rql:"filter,sort"`
+		Age       int       `rql:"filter,sort"`
+		Name      string    `rql:"filter,sort,name=full_name,column=name"`
+		Bio       string    `rql:"filter,search"`
+		Admin     bool      `rql:"filter"`
+		Tags      []string  `rql:"filter"`
+		CreatedAt time.Time `rql:"filter"`
+		DeletedAt *string   `rql:"filter"`
+	})
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr bool
+		wantOut *RediSearchQuery
+	}{
+		{
+			name:  "empty query",
+			input: []byte(`{}`),
+			wantOut: &RediSearchQuery{
+				Query: "*",
+				Num:   25,
+			},
+		},
+		{
+			name: "ranges and equality",
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": { "$gt": 10 } },
+						{ "age": { "$lte": 5 } },
+						{ "id": { "$between": [1, 5] } },
+						{ "full_name": "a8m" },
+						{ "admin": true },
+						{ "created_at": { "$gte": "2020-01-02T00:00:00Z" } }
+					]
+				},
+				"sort": ["-age"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantOut: &RediSearchQuery{
+				Query:     "@age:[(10 +inf]|@age:[-inf 5]|@id:[1 5]|@name:a8m|@admin:{true}|@created_at:[1577923200 +inf]",
+				SortBy:    "age",
+				SortOrder: "DESC",
+				Offset:    20,
+				Num:       10,
+			},
+		},
+		{
+			name: "lists, like, search and not",
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "id": { "$in": [1, 2] } },
+						{ "full_name": { "$nin": ["a8m", "noa m"] } },
+						{ "full_name": { "$like": "ari%" } },
+						{ "full_name": { "$ilike": "a_8%m" } },
+						{ "bio": { "$search": "gopher, go" } },
+						{ "tags": { "$contains": ["go", "rql"] } },
+						{ "$not": { "age": 10 } },
+						{ "deleted_at": { "$null": true } }
+					]
+				},
+				"sort": ["id"]
+			}`),
+			wantOut: &RediSearchQuery{
+				Query:     `(@id:[1 1]|@id:[2 2]) -(@name:a8m|@name:noa\ m) @name:ari* @name:w'a?8*m' @bio:(gopher\, go) (@tags:{go} @tags:{rql}) -@age:[10 10] ismissing(@deleted_at)`,
+				SortBy:    "id",
+				SortOrder: "ASC",
+				Num:       25,
+			},
+		},
+		{
+			name: "nested groups",
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "tags": { "$has": "go" } },
+						{
+							"$or": [
+								{ "age": 1 },
+								{ "$and": [{ "age": 2 }, { "admin": false }] }
+							]
+						}
+					]
+				}
+			}`),
+			wantOut: &RediSearchQuery{
+				Query: "@tags:{go} (@age:[1 1]|(@age:[2 2] @admin:{false}))",
+				Num:   25,
+			},
+		},
+		{
+			name: "range on text",
+			input: []byte(`{
+				"filter": { "full_name": { "$gt": "a" } }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "unsupported op",
+			input: []byte(`{
+				"filter": { "tags": { "$size": 2 } }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "multiple sort fields",
+			input: []byte(`{
+				"sort": ["age", "id"]
+			}`),
+			wantErr: true,
+		},
+	}
+	p, err := NewParser(Config{Model: model, Log: t.Logf})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.ParseRediSearch(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			if !reflect.DeepEqual(out, tt.wantOut) {
+				t.Fatalf("redisearch query:\n\tgot: %#v\n\twant %#v", out, tt.wantOut)
+			}
+		})
+	}
+}
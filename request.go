@@ -0,0 +1,67 @@
+package rql
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrBodyTooLarge is returned by ParseRequest and ParseForm when the request body exceeds the configured
+// MaxBodyBytes. HTTP handlers may map it to a 413 (Request Entity Too Large) status code.
+var ErrBodyTooLarge = errors.New("rql: request body too large")
+
+// ParseRequest parses the query of the given HTTP request into a Param object. The query is read
+// from the body for POST and PUT requests, and from the URL query string (see ParseValues) otherwise.
+// The body is closed after it was read.
+func (p *Parser) ParseRequest(r *http.Request) (*Params, error) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		return p.ParseValues(r.URL.Query())
+	}
+	defer r.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(r.Body, p.MaxBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > p.MaxBodyBytes {
+		return nil, ErrBodyTooLarge
+	}
+	return p.Parse(b)
+}
+
+// ParseForm parses the query of the given HTTP request form into a Param object. The form values are
+// read from the URL query string, and from the body of form-encoded (application/x-www-form-urlencoded)
+// or multipart (multipart/form-data) requests, and they follow the same bracketed grammar as ParseValues.
+// Bodies that exceed the configured MaxBodyBytes are rejected with ErrBodyTooLarge.
+func (p *Parser) ParseForm(r *http.Request) (*Params, error) {
+	if r.Body != nil {
+		r.Body = &limitedBody{ReadCloser: r.Body, n: p.MaxBodyBytes}
+	}
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		err = r.ParseMultipartForm(p.MaxBodyBytes)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseValues(r.Form)
+}
+
+// limitedBody is a request body that fails with ErrBodyTooLarge when more than n bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (l *limitedBody) Read(b []byte) (int, error) {
+	if int64(len(b)) > l.n+1 {
+		b = b[:l.n+1]
+	}
+	n, err := l.ReadCloser.Read(b)
+	if l.n -= int64(n); l.n < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,177 @@
+package rql
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		wantErr error
+		wantOut *Params
+	}{
+		{
+			name:   "post body",
+			method: "POST",
+			target: "/users",
+			body:   `{"filter": {"age": {"$gt": 10}}, "limit": 10}`,
+			wantOut: &Params{
+				Limit:      10,
+				FilterExp:  "age > ?",
+				FilterArgs: []interface{}{10},
+			},
+		},
+		{
+			name:   "get query string",
+			method: "GET",
+			target: "/users?filter[age][$gt]=10&limit=10",
+			wantOut: &Params{
+				Limit:      10,
+				FilterExp:  "age > ?",
+				FilterArgs: []interface{}{10},
+			},
+		},
+		{
+			name:    "body too large",
+			method:  "PUT",
+			target:  "/users",
+			body:    `{"filter": {"name": "` + strings.Repeat("a", 64) + `"}}`,
+			wantErr: ErrBodyTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(Config{
+				Model: new(struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+				MaxBodyBytes: 64,
+				Log:          t.Logf,
+			})
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			out, err := p.ParseRequest(httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want: %v\ngot: %v", tt.wantErr, err)
+			}
+			assertParams(t, out, tt.wantOut)
+		})
+	}
+}
+
+func TestParseForm(t *testing.T) {
+	mpBody, mpType := multipartForm(t, map[string]string{"filter[age][$gt]": "10", "limit": "10"})
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		contentType string
+		wantErr     error
+		wantOut     *Params
+	}{
+		{
+			name:        "form-encoded body",
+			method:      "POST",
+			target:      "/users",
+			body:        "filter[age][$gt]=10&limit=10",
+			contentType: "application/x-www-form-urlencoded",
+			wantOut: &Params{
+				Limit:      10,
+				FilterExp:  "age > ?",
+				FilterArgs: []interface{}{10},
+			},
+		},
+		{
+			name:        "form-encoded body and query string",
+			method:      "POST",
+			target:      "/users?limit=10",
+			body:        "filter[name]=a8m",
+			contentType: "application/x-www-form-urlencoded",
+			wantOut: &Params{
+				Limit:      10,
+				FilterExp:  "name = ?",
+				FilterArgs: []interface{}{"a8m"},
+			},
+		},
+		{
+			name:        "multipart body",
+			method:      "POST",
+			target:      "/users",
+			body:        mpBody,
+			contentType: mpType,
+			wantOut: &Params{
+				Limit:      10,
+				FilterExp:  "age > ?",
+				FilterArgs: []interface{}{10},
+			},
+		},
+		{
+			name:   "get query string",
+			method: "GET",
+			target: "/users?filter[age][$gt]=10&limit=10",
+			wantOut: &Params{
+				Limit:      10,
+				FilterExp:  "age > ?",
+				FilterArgs: []interface{}{10},
+			},
+		},
+		{
+			name:        "body too large",
+			method:      "POST",
+			target:      "/users",
+			body:        "filter[name]=" + strings.Repeat("a", 1<<10),
+			contentType: "application/x-www-form-urlencoded",
+			wantErr:     ErrBodyTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(Config{
+				Model: new(struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+				MaxBodyBytes: 1 << 10,
+				Log:          t.Logf,
+			})
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			out, err := p.ParseForm(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want: %v\ngot: %v", tt.wantErr, err)
+			}
+			assertParams(t, out, tt.wantOut)
+		})
+	}
+}
+
+// multipartForm returns a multipart body that contains the given fields, and its content type.
+func multipartForm(t *testing.T, fields map[string]string) (string, string) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			t.Fatalf("failed to write field: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+	return b.String(), w.FormDataContentType()
+}
@@ -3,11 +3,19 @@ package rql
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"net"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +32,11 @@ type Query struct {
 	Limit int `json:"limit,omitempty"`
 	// Offset must be >= 0.
 	Offset int `json:"offset,omitempty"`
+	// Page is an alternative pagination style to Offset. It is 1-based, and translated to the
+	// offset of the page, i.e. (Page-1)*Limit. It can not be combined with Offset.
+	Page int `json:"page,omitempty"`
+	// PageSize is an alias for Limit that is used with Page. It can not be combined with Limit.
+	PageSize int `json:"pageSize,omitempty"`
 	// Select contains the list of expressions define the value for the `SELECT` clause.
 	// For example:
 	//
@@ -32,6 +45,15 @@ type Query struct {
 	//	}`))
 	//
 	Select []string `json:"select,omitempty"`
+	// Distinct if true, the selected rows should be distinct, i.e. `SELECT DISTINCT`. It is meaningful only
+	// alongside Select, but it is not an error to use it without it. For example:
+	//
+	//	params, err := p.Parse([]byte(`{
+	//		"select": ["name"],
+	//		"distinct": true
+	//	}`))
+	//
+	Distinct bool `json:"distinct,omitempty"`
 	// Sort contains list of expressions define the value for the `ORDER BY` clause.
 	// In order to return the rows in descending order you can prefix your field with `-`.
 	// For example:
@@ -41,6 +63,23 @@ type Query struct {
 	//	}`))
 	//
 	Sort []string `json:"sort,omitempty"`
+	// Group contains the list of fields for the `GROUP BY` clause. The fields must be groupable
+	// (have the "group" option in their tag). For example:
+	//
+	//	params, err := p.Parse([]byte(`{
+	//		"group": ["status", "region"]
+	//	}`))
+	//
+	Group []string `json:"group,omitempty"`
+	// After is the cursor for keyset pagination. It contains the values of the sort fields of the last row
+	// in the previous page, and it is used for building the value of `CursorExp`. For example:
+	//
+	//	params, err := p.Parse([]byte(`{
+	//		"sort": ["id"],
+	//		"after": { "id": 1000 }
+	//	}`))
+	//
+	After map[string]interface{} `json:"after,omitempty"`
 	// Filter is the query object for building the value for the `WHERE` clause.
 	// The full documentation of the supported operators is writtern in the README.
 	// An example for filter object:
@@ -56,6 +95,9 @@ type Query struct {
 	//	}`))
 	//
 	Filter map[string]interface{} `json:"filter,omitempty"`
+	// Negate if true, the filter is negated. i.e. "NOT (...)". It is useful for returning all rows except
+	// the ones that match a saved filter. It has no effect if the filter is empty.
+	Negate bool `json:"negate,omitempty"`
 }
 
 // Params is the parser output after calling to `Parse`. You should pass its
@@ -81,8 +123,12 @@ type Params struct {
 	Offset int
 	// Select contains the expression for the `SELECT` clause defined in the Query.
 	Select string
+	// Distinct reports whether the rows returned by the `SELECT` statement should be distinct, i.e. `SELECT DISTINCT name`.
+	Distinct bool
 	// Sort used as a parameter for the `ORDER BY` clause. For example, "age desc, name".
 	Sort string
+	// Group used as a parameter for the `GROUP BY` clause. For example, "status, region".
+	Group string
 	// FilterExp and FilterArgs come together and used as a parameters for the `WHERE` clause.
 	//
 	// examples:
@@ -93,21 +139,311 @@ type Params struct {
 	// 	   Args: "a8m", 22
 	FilterExp  string
 	FilterArgs []interface{}
+	// Filter is the filter tree that FilterExp was rendered from. It allows query builders to compose
+	// the filter with their own placeholders. See FilterNode for more info.
+	Filter *FilterNode
+	// FilterNamedArgs maps the named parameters in FilterExp to their values. It is populated only if the
+	// parser was configured with NamedParams, and it can be converted to `pgx.NamedArgs`. For example:
+	//
+	//	Exp: "age > :age_1 AND name LIKE :name_2"
+	//	NamedArgs: {"age_1": 22, "name_2": "a8m"}
+	//
+	FilterNamedArgs map[string]interface{}
+	// FilterDebug is the filter expression with a comment next to each predicate, that describes the field and the
+	// operator that produced it. It is populated only if the parser was configured with DebugComments, and it is
+	// used only for debugging (i.e. logging). For example:
+	//
+	//	Exp: "age > ? AND name LIKE ?"
+	//	Debug: "age > ? /* field: age op: gt */ AND name LIKE ? /* field: name op: like */"
+	//
+	FilterDebug string
+	// CursorExp and CursorArgs come together and used for keyset pagination. The expression should be
+	// combined with FilterExp using AND, and its arguments follow the FilterArgs. The fields are compared
+	// according to their sort direction (ascending uses ">", and descending uses "<"). For example:
+	//
+	//	Sort: "age desc, id"
+	//	Exp: "(age < ? OR (age = ? AND id > ?))"
+	//	Args: 22, 22, 1000
+	//
+	// If NamedParams is enabled, the cursor parameters are added to FilterNamedArgs as well.
+	CursorExp  string
+	CursorArgs []interface{}
 	// PositionalParams if true will append a numerical suffix to the ParamSymbol, i.e. ?1, ?2, etc.
 	PositionalParams bool
 	// ParamSymbol is the placehold for parameters in the Filter expression the default is '?', postgres for example uses '$'
 	ParamSymbol string
+	// ParamOffset is the number of the first positional parameter in the Filter expression.
+	ParamOffset int
+	// PaginationParams if true renders the limit and the offset as parameters in the `SQL` method output,
+	// i.e. "LIMIT ? OFFSET ?". Their values follow the FilterArgs in the `SQLArgs` method output.
+	PaginationParams bool
+	// Joins contains the join clauses of the fields that were referenced by the filter or the sort expressions.
+	// Joins are declared using the "join" option in the struct tag, and appear once in the order they were referenced.
+	// For example:
+	//
+	//	type User struct {
+	//		City string `rql:"filter,sort,column=addresses.city,join=JOIN addresses ON addresses.user_id = users.id"`
+	//	}
+	//
+	Joins []string
+	// Dialect is the SQL dialect used for rendering the `SQL` method output.
+	Dialect Dialect
+	// UsedOps maps each column that was referenced by the filter expression to the operators that
+	// were applied on it. For example: {"age": ["gt", "lt"], "name": ["like"]}. Useful for index-advisor tooling.
+	UsedOps map[string][]Op
+}
+
+// SQL returns the clauses that follow the `FROM` clause of a `SELECT` statement: the joins, the `WHERE`,
+// the `GROUP BY`, the `ORDER BY` and the pagination clause, rendered according to the configured dialect. For example:
+//
+//	rows, err := db.Query("SELECT * FROM users "+params.SQL(), params.FilterArgs...)
+func (p *Params) SQL() string {
+	var b strings.Builder
+	p.where(&b)
+	if p.Group != "" {
+		b.WriteString("GROUP BY ")
+		b.WriteString(p.Group)
+		b.WriteByte(' ')
+	}
+	if p.Sort != "" {
+		b.WriteString("ORDER BY ")
+		b.WriteString(p.Sort)
+		b.WriteByte(' ')
+	}
+	args := p.paginationArgs()
+	if p.PaginationParams {
+		for i := range args {
+			args[i] = p.param(len(p.FilterArgs) + i)
+		}
+	}
+	// unlimited queries omit the limit, and the pagination clause altogether if there is no offset.
+	switch {
+	case p.Dialect == DialectOracle:
+		fmt.Fprintf(&b, "OFFSET %v ROWS", args[0])
+		if len(args) > 1 {
+			fmt.Fprintf(&b, " FETCH NEXT %v ROWS ONLY", args[1])
+		}
+	case p.Limit > 0:
+		fmt.Fprintf(&b, "LIMIT %v", args[0])
+		if len(args) > 1 {
+			fmt.Fprintf(&b, " OFFSET %v", args[1])
+		}
+	case len(args) > 0:
+		fmt.Fprintf(&b, "OFFSET %v", args[0])
+	}
+	return strings.TrimSuffix(b.String(), " ")
+}
+
+// SQLArgs returns the arguments of the `SQL` method output. i.e. the FilterArgs, followed by the
+// limit and the offset if the parser was configured with PaginationParams. For example:
+//
+//	rows, err := db.Query("SELECT * FROM users "+params.SQL(), params.SQLArgs()...)
+func (p *Params) SQLArgs() []interface{} {
+	if !p.PaginationParams {
+		return p.FilterArgs
+	}
+	args := make([]interface{}, 0, len(p.FilterArgs)+2)
+	args = append(args, p.FilterArgs...)
+	return append(args, p.paginationArgs()...)
+}
+
+// paginationArgs returns the values of the pagination clause in their order in the dialect syntax.
+func (p *Params) paginationArgs() []interface{} {
+	switch {
+	case p.Dialect == DialectOracle && p.Limit == 0:
+		return []interface{}{p.Offset}
+	case p.Dialect == DialectOracle:
+		return []interface{}{p.Offset, p.Limit}
+	case p.Limit == 0 && p.Offset > 0:
+		return []interface{}{p.Offset}
+	case p.Limit == 0:
+		return nil
+	case p.Offset > 0:
+		return []interface{}{p.Limit, p.Offset}
+	default:
+		return []interface{}{p.Limit}
+	}
+}
+
+// param returns the placeholder of the i-th (zero-based) parameter of the SQL method output.
+func (p *Params) param(i int) string {
+	if p.PositionalParams {
+		return fmt.Sprintf("%s%d", p.ParamSymbol, i+p.ParamOffset)
+	}
+	return p.ParamSymbol
+}
+
+// CountExp returns the clauses that follow the `FROM` clause of a `SELECT COUNT(*)` statement, and their arguments.
+// i.e. the joins and the `WHERE` clause of the filter. The sort, the group, the cursor and the pagination (limit and
+// offset) are excluded, in order to count all rows that match the filter. For example:
+//
+//	exp, args := params.CountExp()
+//	err := db.QueryRow("SELECT COUNT(*) FROM users "+exp, args...).Scan(&total)
+func (p *Params) CountExp() (string, []interface{}) {
+	var b strings.Builder
+	p.where(&b)
+	return strings.TrimSuffix(b.String(), " "), p.FilterArgs
+}
+
+// NeedsScan reports whether the filter would require a table scan given the available indexes, i.e. none of the
+// indexes has a leading column that is filtered using an equality or a range comparison. Each index is given as
+// its list of columns, in their order. For example:
+//
+//	if params.NeedsScan([][]string{{"email"}, {"org_id", "created_at"}}) {
+//		log.Printf("slow query: %s", params.FilterExp)
+//	}
+//
+// It is advisory only and based on UsedOps, and does not take into account disjunctions or the database planner.
+func (p *Params) NeedsScan(indexes [][]string) bool {
+	for _, index := range indexes {
+		if len(index) == 0 {
+			continue
+		}
+		for _, op := range p.UsedOps[index[0]] {
+			if indexOps[op] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// indexOps holds the operators that can be satisfied using an index on their column.
+var indexOps = map[Op]bool{
+	EQ:      true,
+	IN:      true,
+	LT:      true,
+	LTE:     true,
+	GT:      true,
+	GTE:     true,
+	BETWEEN: true,
+}
+
+// where writes the joins and the `WHERE` clause to the given builder.
+func (p *Params) where(b *strings.Builder) {
+	for _, j := range p.Joins {
+		b.WriteString(j)
+		b.WriteByte(' ')
+	}
+	if p.FilterExp != "" {
+		b.WriteString("WHERE ")
+		b.WriteString(p.FilterExp)
+		b.WriteByte(' ')
+	}
 }
 
-// ParseError is type of error returned when there is a parsing problem.
+// ErrNegativeUint is the validation error for negative operands on unsigned integer fields.
+// It can be checked on the errors returned by Parse using errors.Is.
+var ErrNegativeUint = errors.New("not an unsigned integer")
+
+// ErrMatchAll is the error for filters without conditions when RejectMatchAll is set.
+// It can be checked on the errors returned by Parse using errors.Is.
+var ErrMatchAll = errors.New("filter would match all rows")
+
+// ErrorCode classifies the errors returned by the parser, i.e. for mapping them to API responses.
+type ErrorCode string
+
+// Error codes of the ParseError.
+const (
+	// ErrInvalidQuery is the code of malformed queries, i.e. an invalid JSON or a "$or" that is not an array.
+	ErrInvalidQuery ErrorCode = "invalid_query"
+	// ErrUnknownField is the code of keys that do not exist in the model.
+	ErrUnknownField ErrorCode = "unknown_field"
+	// ErrFieldNotAllowed is the code of fields that can not be used in the query part, i.e. a non-sortable field.
+	ErrFieldNotAllowed ErrorCode = "field_not_allowed"
+	// ErrInvalidOp is the code of operators that can not be applied on a field.
+	ErrInvalidOp ErrorCode = "invalid_op"
+	// ErrTypeMismatch is the code of values that do not match the type (or the format) of their field.
+	ErrTypeMismatch ErrorCode = "type_mismatch"
+	// ErrInvalidValue is the code of values that are rejected by the field options, i.e. out of its bounds.
+	ErrInvalidValue ErrorCode = "invalid_value"
+	// ErrLimitExceeded is the code of queries that exceed the configured limits, i.e. the LimitMaxValue.
+	ErrLimitExceeded ErrorCode = "limit_exceeded"
+	// ErrCanceled is the code of parse calls whose context is done. The error wraps the context error.
+	ErrCanceled ErrorCode = "canceled"
+)
+
+// ParseError is type of error returned when there is a parsing problem. Its details can be inspected
+// using errors.As. For example:
+//
+//	var perr *rql.ParseError
+//	if errors.As(err, &perr) && perr.Code == rql.ErrUnknownField {
+//		http.Error(w, "unknown field: "+perr.Field, http.StatusBadRequest)
+//	}
 type ParseError struct {
-	msg string
+	// Code classifies the error.
+	Code ErrorCode
+	// Field is the name of the offending field (or the query key, i.e. "limit"), if there is one.
+	Field string
+	// Value is the raw value of the offending field, if there is one.
+	Value interface{}
+	msg   string
+	err   error
 }
 
 func (p ParseError) Error() string {
 	return p.msg
 }
 
+// Unwrap returns the underlying validation error, if there is one. For example:
+//
+//	if errors.Is(err, rql.ErrNegativeUint) {
+//		// ...
+//	}
+func (p ParseError) Unwrap() error {
+	return p.err
+}
+
+// ParseErrors is the list of the field errors that were collected when CollectErrors is set. It is wrapped
+// by the returned ParseError, and can be inspected using errors.As. For example:
+//
+//	var errs rql.ParseErrors
+//	if errors.As(err, &errs) {
+//		for _, e := range errs {
+//			fmt.Println(e.Field, e.Code)
+//		}
+//	}
+type ParseErrors []*ParseError
+
+// Error returns the messages of the errors, separated by "; ".
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the collected errors, in order to inspect them using errors.Is and errors.As.
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = e[i]
+	}
+	return errs
+}
+
+// Is reports whether one of the collected errors matches the given target. Unlike Unwrap, that errors.Is
+// follows only since Go 1.20, it is supported by all Go versions.
+func (e ParseErrors) Is(target error) bool {
+	for i := range e {
+		if errors.Is(e[i], target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first collected error that matches the given target, and sets the target to it. See Is.
+func (e ParseErrors) As(target interface{}) bool {
+	for i := range e {
+		if errors.As(e[i], target) {
+			return true
+		}
+	}
+	return false
+}
+
 type Validator func(Op, FieldMeta, interface{}) error
 type Converter func(Op, FieldMeta, interface{}) interface{}
 
@@ -128,19 +464,92 @@ type FieldMeta struct {
 	Sortable bool
 	// Has a "filter" option in the tag.
 	Filterable bool
+	// Has a "group" option in the tag.
+	Groupable bool
+	// Has a "select" (or "selectable") option in the tag. Selectable fields are the expansion of the select
+	// wildcard, and if the model has any, the only fields that can be selected.
+	Selectable bool
+	// Has a "search" option in the tag. Only text fields can be searchable, and they accept the `$search` op.
+	Searchable bool
+	// Has a "likecast" option in the tag. It allows the `$like` and `$ilike` ops on non-text fields by casting
+	// the column to text, i.e. "CAST(id AS TEXT) LIKE ?". The operand is validated as a string pattern.
+	LikeCast bool
 	// All supported operators for this field.
 	FilterOps map[string]bool
+	// AvailableOps lists the prefixed operators that can be applied on this field (i.e. "$eq"), after applying the
+	// "ops" whitelist of the tag. It is empty for non-filterable fields.
+	AvailableOps []string
 	// Type of the field
 	Type reflect.Type
-	// Time layout
+	// Kind is the kind of the field type, i.e. reflect.String or reflect.Struct for time fields.
+	Kind reflect.Kind
+	// Time layout. If the field accepts multiple layouts, it is the first one.
 	Layout string
+	// Layouts holds all accepted time layouts, in the order they are tried.
+	Layouts []string
+	// Nullable is true if the field is a pointer or a `sql.Null*` type.
+	Nullable bool
+	// Has a "nonempty" option in the tag. Empty string operands are rejected.
+	NonEmpty bool
+	// Has a "nonblank" option in the tag. Empty and whitespace-only string operands are rejected.
+	NonBlank bool
+	// Placement of NULL values when sorting by this field. Set by the "nulls" option in the tag,
+	// for example: "nulls=last". It can be overridden by the sort expression.
+	Nulls Nulls
+	// Dir is the direction of this field when it is sorted without a "+" or "-" prefix. Set by the "dir" option
+	// in the tag, for example: "dir=desc". An explicit prefix in the sort expression takes precedence.
+	Dir Direction
+	// Collation of the column when sorting by this field. Set by the "collate" option in the tag,
+	// for example: "collate=en_US". Only text fields can be collated.
+	Collate string
+	// Via is a denormalized column that is used instead of the field column in the generated
+	// expressions, while the field name remains unchanged. Set by the "via" option in the tag.
+	Via string
+	// Expr is an SQL expression that is rendered instead of the column of this field, i.e. for computed
+	// columns. Set by the ColumnExpr map in the config.
+	Expr string
+	// Table that qualifies the column of this field. Set by the "table" option in the tag.
+	// It defaults to the TablePrefix in the config.
+	Table string
+	// Join clause required for querying this field. Set by the "join" option in the tag.
+	Join string
+	// Whitelist of operators that are allowed on this field. Set by the "ops" option in the tag,
+	// for example: "ops=eq|neq". A nil map means all supported operators are allowed.
+	AllowedOps map[string]bool
+	// Has a "json" option in the tag. JSON fields hold documents (i.e. json.RawMessage, maps or slices), and they
+	// are filtered by the paths of their elements, i.e. "metadata.tier" or "scores.0".
+	JSON bool
+	// Path holds the segments of a JSON path field relative to its JSON field, i.e. ["tier"] for "metadata.tier".
+	Path []string
+	// Cast is the type that the value extracted by a JSON path is casted to, i.e. "numeric". It is set on the
+	// predicates of JSON path fields according to the type of their values, and it is empty for strings.
+	Cast string
+	// Min and Max are the bounds of the filter values of this field. Set by the "min" and "max" options in the
+	// tag, for example: "min=0,max=1000". They hold a float64 for numeric fields, and a time.Time for time fields.
+	// A nil bound means no bound.
+	Min, Max interface{}
+	// Range holds the start and the end fields of a range field, that is configured using the Ranges config.
+	Range []*FieldMeta
+	// DefaultOp is the operator that is applied when a bare value is given for this field. Set by the "op"
+	// option in the tag (i.e. "op=like"), and defaults to the DefaultStringOp in the config for string fields
+	// that support it.
+	DefaultOp Op
+	// OpColumns maps operators to the columns that they are applied on, instead of Column. Set by the "<op>column"
+	// options in the tag, i.e. "eqcolumn=name_lower" filters `$eq` on a normalized column, and the rest on Column.
+	OpColumns map[Op]string
+	// timeFn is the TimeFn of the config. If set, it parses the time values instead of the layouts.
+	timeFn func(*FieldMeta, string) (time.Time, error)
 }
 
 // A Parser parses various types. The result from the Parse method is a Param object.
-// It is safe for concurrent use by multiple goroutines except for configuration changes.
+//
+// A Parser is safe for concurrent use by multiple goroutines, and should be created once and shared.
+// Its configuration and fields are read-only after NewParser returns, and each call uses its own parse
+// state, taken from a pool. The Config of a Parser must not be modified after it was created.
 type Parser struct {
 	Config
-	fields map[string]*Field
+	fields     map[string]*Field
+	selectable []*FieldMeta // fields with the "select" option, in their declaration order
 }
 
 // NewParser creates a new Parser. it fails if the configuration is invalid.
@@ -165,12 +574,17 @@ func NewParserF(c Config, fields []*Field) (*Parser, error) {
 	}
 
 	m := make(map[string]*Field, len(fields))
+	var selectable []*FieldMeta
 	for _, v := range fields {
 		m[v.Name] = v
+		if v.Selectable {
+			selectable = append(selectable, v.FieldMeta)
+		}
 	}
 	p := &Parser{
-		Config: c,
-		fields: m,
+		Config:     c,
+		fields:     m,
+		selectable: selectable,
 	}
 	return p, nil
 }
@@ -188,16 +602,32 @@ func MustNewParser(c Config) *Parser {
 // Parse parses the given buffer into a Param object. It returns an error
 // if the JSON is invalid, or its values don't follow the schema of rql.
 func (p *Parser) Parse(b []byte) (pr *Params, err error) {
+	return p.ParseContext(context.Background(), b)
+}
+
+// ParseContext is like Parse, but it stops parsing the filter and returns an error that wraps the context
+// error, if the given context is done. The context is checked before descending into each logical group
+// of the filter, in order to bound the parsing time of large filters. For example:
+//
+//	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Millisecond)
+//	defer cancel()
+//	params, err := parser.ParseContext(ctx, b)
+func (p *Parser) ParseContext(ctx context.Context, b []byte) (pr *Params, err error) {
 	q := &Query{}
 	if err := q.UnmarshalJSON(b); err != nil {
-		return nil, &ParseError{"decoding buffer to *Query: " + err.Error()}
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
 	}
-	return p.ParseQuery(q)
+	return p.ParseQueryContext(ctx, q)
 }
 
 // ParseQuery parses the given struct into a Param object. It returns an error
 // if one of the query values don't follow the schema of rql.
 func (p *Parser) ParseQuery(q *Query) (pr *Params, err error) {
+	return p.ParseQueryContext(context.Background(), q)
+}
+
+// ParseQueryContext is like ParseQuery, but it stops parsing when the given context is done. See ParseContext.
+func (p *Parser) ParseQueryContext(ctx context.Context, q *Query) (pr *Params, err error) {
 	defer func() {
 		if e := recover(); e != nil {
 			perr, ok := e.(*ParseError)
@@ -208,35 +638,173 @@ func (p *Parser) ParseQuery(q *Query) (pr *Params, err error) {
 			pr = nil
 		}
 	}()
-	pr = &Params{
-		Limit: p.DefaultLimit,
+	ps := p.newParseState()
+	ps.ctx = ctx
+	ps.done()
+	pr = ps.query(q)
+	parseStatePool.Put(ps)
+	return
+}
+
+// query builds the parser output of the given query.
+func (p *parseState) query(q *Query) *Params {
+	pr := &Params{}
+	pr.Limit, pr.Offset = p.pagination(q)
+	p.root = p.filter(q)
+	p.render(p.root)
+	pr.Filter = p.root
+	pr.FilterExp = p.String()
+	n := len(p.values)
+	pr.FilterArgs = p.values[:n:n]
+	if p.DebugComments {
+		pr.FilterDebug = p.debug(p.root)
+	}
+	pr.Sort = p.querySort(q)
+	pr.PositionalParams = p.PositionalParams
+	pr.ParamSymbol = p.ParamSymbol
+	pr.ParamOffset = p.ParamOffset
+	pr.PaginationParams = p.PaginationParams
+	pr.Dialect = p.Dialect
+	if len(q.After) > 0 {
+		p.Reset()
+		p.cursor(q.After)
+		pr.CursorExp = p.String()
+		pr.CursorArgs = p.values[n:]
+	}
+	if p.NamedParams {
+		pr.FilterNamedArgs = make(map[string]interface{}, len(p.names))
+		for i, name := range p.names {
+			pr.FilterNamedArgs[name] = p.values[i]
+		}
+	}
+	pr.Group = p.group(q.Group)
+	pr.Joins = p.joins
+	pr.UsedOps = p.usedOps
+	pr.Select = p.selectExp(p.keys(q.Select))
+	pr.Distinct = q.Distinct
+	return pr
+}
+
+// maxInt is the maximum value of an int. math.MaxInt is not available before Go 1.17.
+const maxInt = int(^uint(0) >> 1)
+
+// pagination validates the pagination fields of the given query, and returns its limit and offset.
+func (p *parseState) pagination(q *Query) (limit, offset int) {
+	limit = p.DefaultLimit
+	if p.AllowUnlimited {
+		limit = 0
+	}
+	expect(q.Limit == 0 || q.PageSize == 0, "limit and pageSize can not be used together")
+	expect(q.Offset == 0 || q.Page == 0, "offset and page can not be used together")
+	expectField(q.Offset >= 0, ErrInvalidValue, "offset", q.Offset, "offset must be greater than or equal to 0")
+	offset = q.Offset
+	if q.PageSize != 0 {
+		expectField(q.PageSize > 0 && q.PageSize <= p.LimitMaxValue, ErrLimitExceeded, "pageSize", q.PageSize, "pageSize must be greater than 0 and less than or equal to %d", p.LimitMaxValue)
+		limit = q.PageSize
 	}
-	expect(q.Offset >= 0, "offset must be greater than or equal to 0")
-	pr.Offset = q.Offset
 	if q.Limit != 0 {
-		expect(q.Limit > 0 && q.Limit <= p.LimitMaxValue, "limit must be greater than 0 and less than or equal to %d", p.LimitMaxValue)
-		pr.Limit = q.Limit
+		expectField(q.Limit > 0 && q.Limit <= p.LimitMaxValue, ErrLimitExceeded, "limit", q.Limit, "limit must be greater than 0 and less than or equal to %d", p.LimitMaxValue)
+		limit = q.Limit
+	}
+	if q.Page != 0 {
+		expectField(q.Page > 0, ErrInvalidValue, "page", q.Page, "page must be greater than 0")
+		expect(limit > 0, "page can not be used without a limit")
+		expectField(q.Page-1 <= maxInt/limit, ErrLimitExceeded, "page", q.Page, "page is too large")
+		offset = (q.Page - 1) * limit
+		expectField(offset >= 0, ErrLimitExceeded, "page", q.Page, "page is too large")
+	}
+	expectField(p.OffsetMaxValue == 0 || offset <= p.OffsetMaxValue, ErrLimitExceeded, "offset", offset, "offset must be less than or equal to %d", p.OffsetMaxValue)
+	return limit, offset
+}
+
+// querySort builds the sort expression of the given query, merged with the default sort.
+func (p *parseState) querySort(q *Query) string {
+	expectField(p.MaxSortFields == 0 || len(q.Sort) <= p.MaxSortFields, ErrLimitExceeded, "sort", q.Sort, "sort must have at most %d fields", p.MaxSortFields)
+	switch {
+	case len(q.Sort) == 0:
+		return p.sort(p.DefaultSort)
+	case p.DefaultSortMerge == SortMergeAppend:
+		return p.sort(q.Sort, p.DefaultSort...)
+	default:
+		return p.sort(q.Sort)
+	}
+}
+
+// Validate validates the given buffer against the parser model, without building the SQL expressions and
+// their arguments. It returns the same errors that Parse returns for invalid queries (i.e. unknown fields,
+// unsupported operators, mismatched types or pagination out of bounds). Useful for rejecting invalid filters
+// before storing them. For example:
+//
+//	if err := parser.Validate(savedFilter); err != nil {
+//		return err
+//	}
+func (p *Parser) Validate(b []byte) (err error) {
+	q := &Query{}
+	if err := q.UnmarshalJSON(b); err != nil {
+		return &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = perr
+		}
+	}()
+	ps := p.newParseState()
+	ps.pagination(q)
+	ps.filter(q)
+	ps.querySort(q)
+	// the cursor values are validated in its (small) expression.
+	if len(q.After) > 0 {
+		ps.cursor(q.After)
+	}
+	ps.group(q.Group)
+	ps.selectExp(ps.keys(q.Select))
+	parseStatePool.Put(ps)
+	return nil
+}
+
+// ValidateAgainst validates the given saved query against the parser model, and returns the filter,
+// sort and group keys that do not exist in the model (i.e. fields that were removed) in sorted order, instead of failing entirely.
+// This allows prompting the user to fix a stale saved query. An error is returned if the query is
+// invalid for any other reason, like an invalid JSON or a value that does not match its field type.
+func (p *Parser) ValidateAgainst(b []byte) (missing []string, err error) {
+	q := &Query{}
+	if err := q.UnmarshalJSON(b); err != nil {
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
 	}
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = perr
+			missing = nil
+		}
+	}()
 	ps := p.newParseState()
+	ps.lenient = true
 	ps.and(q.Filter)
-	pr.FilterExp = ps.String()
-	pr.FilterArgs = ps.values
-	pr.Sort = p.sort(q.Sort)
-	pr.PositionalParams = p.PositionalParams
-	pr.ParamSymbol = p.ParamSymbol
-	if len(pr.Sort) == 0 && len(p.DefaultSort) > 0 {
-		pr.Sort = p.sort(p.DefaultSort)
-	}
-	pr.Select = strings.Join(q.Select, ", ")
+	ps.raise()
+	ps.sort(q.Sort)
+	ps.group(q.Group)
+	missing = ps.missing
+	sort.Strings(missing)
 	parseStatePool.Put(ps)
-	return
+	return missing, nil
 }
 
+// GetFields returns the fields of the parser sorted by their names, i.e. for generating the documentation of
+// an endpoint. The fields must not be modified.
 func (p *Parser) GetFields() []*Field {
 	fields := make([]*Field, 0, len(p.fields))
 	for _, v := range p.fields {
 		fields = append(fields, v)
 	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
 	return fields
 }
 
@@ -264,18 +832,49 @@ func Column(s string) string {
 }
 
 func GetSupportedOps(f *FieldMeta) []Op {
+	ops := getSupportedOps(f)
+	if len(ops) > 0 && f.Nullable {
+		ops = append(ops, NULL)
+	}
+	if f.Searchable && isText(f.Type) {
+		ops = append(ops, SEARCH)
+	}
+	if isIP(f.Type) {
+		ops = append(ops, INSUBNET)
+	}
+	return ops
+}
+
+func getSupportedOps(f *FieldMeta) []Op {
 	t := f.Type
+	if isBig(t) {
+		return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
+	}
+	if isUnmarshaler(t) {
+		if isOrdered(t) {
+			return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
+		}
+		return []Op{EQ, NEQ, IN, NIN}
+	}
 	switch t.Kind() {
 	case reflect.Bool:
 		return []Op{EQ, NEQ}
 	case reflect.String:
-		return []Op{EQ, NEQ, LT, LTE, GT, GTE, LIKE}
+		return []Op{EQ, NEQ, LT, LTE, GT, GTE, LIKE, ILIKE, STARTSWITH, ENDSWITH, INCLUDES, CONTAINS, BETWEEN, IN, NIN}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return []Op{EQ, NEQ, LT, LTE, GT, GTE}
+		return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return []Op{EQ, NEQ, LT, LTE, GT, GTE}
+		return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
 	case reflect.Float32, reflect.Float64:
-		return []Op{EQ, NEQ, LT, LTE, GT, GTE}
+		return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return []Op{}
+		}
+		if len(getSupportedOps(elemMeta(f))) == 0 {
+			return []Op{SIZE}
+		}
+		return []Op{SIZE, HAS, CONTAINS}
 	case reflect.Struct:
 		switch v := reflect.Zero(t); v.Interface().(type) {
 		case sql.NullBool:
@@ -283,14 +882,14 @@ func GetSupportedOps(f *FieldMeta) []Op {
 		case sql.NullString:
 			return []Op{EQ, NEQ}
 		case sql.NullInt64:
-			return []Op{EQ, NEQ, LT, LTE, GT, GTE}
+			return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
 		case sql.NullFloat64:
-			return []Op{EQ, NEQ, LT, LTE, GT, GTE}
-		case time.Time:
-			return []Op{EQ, NEQ, LT, LTE, GT, GTE}
+			return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
+		case time.Time, sql.NullTime:
+			return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
 		default:
 			if v.Type().ConvertibleTo(reflect.TypeOf(time.Time{})) {
-				return []Op{EQ, NEQ, LT, LTE, GT, GTE}
+				return []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN}
 			}
 			return []Op{}
 		}
@@ -300,8 +899,18 @@ func GetSupportedOps(f *FieldMeta) []Op {
 }
 
 func GetConverterFn(f *FieldMeta) Converter {
-	layout := f.Layout
+	return convertList(getConverterFn(f))
+}
+
+func getConverterFn(f *FieldMeta) Converter {
+	parse := f.timeParser()
 	t := f.Type
+	if isBig(t) {
+		return convertBig(t)
+	}
+	if isUnmarshaler(t) {
+		return convertUnmarshaler(t)
+	}
 	switch t.Kind() {
 	case reflect.Bool:
 		return valueFn
@@ -313,6 +922,8 @@ func GetConverterFn(f *FieldMeta) Converter {
 		return convertInt
 	case reflect.Float32, reflect.Float64:
 		return valueFn
+	case reflect.Slice, reflect.Array:
+		return getConverterFn(elemMeta(f))
 	case reflect.Struct:
 		switch v := reflect.Zero(t); v.Interface().(type) {
 		case sql.NullBool:
@@ -323,11 +934,11 @@ func GetConverterFn(f *FieldMeta) Converter {
 			return convertInt
 		case sql.NullFloat64:
 			return valueFn
-		case time.Time:
-			return convertTime(layout)
+		case time.Time, sql.NullTime:
+			return convertTimeFn(parse)
 		default:
 			if v.Type().ConvertibleTo(reflect.TypeOf(time.Time{})) {
-				return convertTime(layout)
+				return convertTimeFn(parse)
 			}
 		}
 	}
@@ -335,8 +946,21 @@ func GetConverterFn(f *FieldMeta) Converter {
 }
 
 func GetValidateFn(f *FieldMeta) Validator {
+	if fn := getValidateFn(f); fn != nil {
+		return validateList(fn)
+	}
+	return nil
+}
+
+func getValidateFn(f *FieldMeta) Validator {
 	t := f.Type
-	layout := f.Layout
+	parse := f.timeParser()
+	if isBig(t) {
+		return validateBig(t)
+	}
+	if isUnmarshaler(t) {
+		return validateUnmarshaler(t)
+	}
 	switch t.Kind() {
 	case reflect.Bool:
 		return validateBool
@@ -348,6 +972,8 @@ func GetValidateFn(f *FieldMeta) Validator {
 		return validateUInt
 	case reflect.Float32, reflect.Float64:
 		return validateFloat
+	case reflect.Slice, reflect.Array:
+		return getValidateFn(elemMeta(f))
 	case reflect.Struct:
 		switch v := reflect.Zero(t); v.Interface().(type) {
 		case sql.NullBool:
@@ -358,19 +984,83 @@ func GetValidateFn(f *FieldMeta) Validator {
 			return validateInt
 		case sql.NullFloat64:
 			return validateFloat
-		case time.Time:
-			return validateTime(layout)
+		case time.Time, sql.NullTime:
+			return validateTimeFn(parse)
 		default:
 			if !v.Type().ConvertibleTo(reflect.TypeOf(time.Time{})) {
 				return nil
 			}
-			return validateTime(layout)
+			return validateTimeFn(parse)
 		}
 	default:
 		return nil
 	}
 }
 
+// timeParser returns the function that parses the time values of the field. It is the TimeFn of the
+// config if it is set, and parsing using the field layouts otherwise.
+func (f *FieldMeta) timeParser() func(string) (time.Time, error) {
+	if fn := f.timeFn; fn != nil {
+		return func(s string) (time.Time, error) { return fn(f, s) }
+	}
+	return layoutsParser(f.timeLayouts())
+}
+
+// layoutsParser returns a function that parses time values using the given layouts.
+func layoutsParser(layouts []string) func(string) (time.Time, error) {
+	return func(s string) (time.Time, error) { return parseTime(layouts, s) }
+}
+
+// timeLayouts returns the time layouts accepted by the field.
+func (f *FieldMeta) timeLayouts() []string {
+	if len(f.Layouts) > 0 {
+		return f.Layouts
+	}
+	return []string{f.Layout}
+}
+
+// parseBound parses the given min or max option of the field. Only numeric and time fields can be bounded.
+func (f *FieldMeta) parseBound(s string) (interface{}, error) {
+	switch {
+	case isNumber(f.Type):
+		return strconv.ParseFloat(s, 64)
+	case isTime(f.Type):
+		return f.timeParser()(s)
+	default:
+		return nil, errors.New("bounds are supported only on numeric and time fields")
+	}
+}
+
+// checkBounds validates the given converted value against the bounds of the field.
+func (f *FieldMeta) checkBounds(v interface{}) error {
+	switch v := v.(type) {
+	case time.Time:
+		if min, ok := f.Min.(time.Time); ok && v.Before(min) {
+			return fmt.Errorf("value %s must be greater than or equal to %s", v.Format(f.Layout), min.Format(f.Layout))
+		}
+		if max, ok := f.Max.(time.Time); ok && v.After(max) {
+			return fmt.Errorf("value %s must be less than or equal to %s", v.Format(f.Layout), max.Format(f.Layout))
+		}
+	case int, float64:
+		n := reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0))).Float()
+		if min, ok := f.Min.(float64); ok && n < min {
+			return fmt.Errorf("value %v must be greater than or equal to %v", v, min)
+		}
+		if max, ok := f.Max.(float64); ok && n > max {
+			return fmt.Errorf("value %v must be less than or equal to %v", v, max)
+		}
+	}
+	return nil
+}
+
+// elemMeta returns a copy of the given array field with the type of its elements. It is used
+// for validating and converting the operands of the HAS op.
+func elemMeta(f *FieldMeta) *FieldMeta {
+	ef := *f
+	ef.Type = indirect(f.Type.Elem())
+	return &ef
+}
+
 // init initializes the parser parsing state. it scans the fields
 // in a breath-first-search order and for each one of the field calls parseField.
 func (p *Parser) init() error {
@@ -403,6 +1093,106 @@ func (p *Parser) init() error {
 			p.Log("ignore embedded field %q that is not struct type", f.Name)
 		}
 	}
+	// the fields were visited in reverse order. restore the declaration order of the selectable fields.
+	for i, j := 0, len(p.selectable)-1; i < j; i, j = i+1, j-1 {
+		p.selectable[i], p.selectable[j] = p.selectable[j], p.selectable[i]
+	}
+	// the config keys are resolved like the field names, i.e. normalized using the NormalizeKeyFn.
+	for name, expr := range p.ColumnExpr {
+		f, ok := p.fields[p.key(name)]
+		if !ok {
+			return fmt.Errorf("rql: column expression of unknown field %q", name)
+		}
+		f.Expr = expr
+	}
+	for name, bounds := range p.Ranges {
+		if err := p.parseRange(name, bounds); err != nil {
+			return err
+		}
+	}
+	if err := p.validateDefaultFilter(); err != nil {
+		return err
+	}
+	return p.validateDefaultSort()
+}
+
+// validateDefaultFilter validates the DefaultFilter against the model.
+func (p *Parser) validateDefaultFilter() (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = fmt.Errorf("rql: invalid default filter: %v", perr)
+		}
+	}()
+	ps := p.newParseState()
+	ps.trusted = true
+	ps.and(p.DefaultFilter)
+	ps.raise()
+	parseStatePool.Put(ps)
+	return nil
+}
+
+// validateDefaultSort validates the DefaultSort (and the SortTiebreaker) against the model, instead of
+// failing on the first query that omits its sort.
+func (p *Parser) validateDefaultSort() (err error) {
+	name := "sort tiebreaker"
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			err = fmt.Errorf("rql: invalid %s: %v", name, perr)
+		}
+	}()
+	ps := p.newParseState()
+	// the tiebreaker is validated on its own, since it is not applied to an empty sort clause.
+	for _, field := range p.SortTiebreaker {
+		ps.sortTerm(field)
+	}
+	name = "default sort"
+	ps.sort(p.DefaultSort)
+	parseStatePool.Put(ps)
+	return nil
+}
+
+// parseRange adds a range field that is composed of the given start and end fields.
+func (p *Parser) parseRange(name string, bounds [2]string) error {
+	name, bounds = p.key(name), [2]string{p.key(bounds[0]), p.key(bounds[1])}
+	if _, ok := p.fields[name]; ok {
+		return fmt.Errorf("rql: range %q collides with another field", name)
+	}
+	fs := make([]*FieldMeta, len(bounds))
+	for i, b := range bounds {
+		f, ok := p.fields[b]
+		if !ok || !f.Filterable {
+			return fmt.Errorf("rql: field %q of range %q is not a filterable field", b, name)
+		}
+		if !isTime(f.Type) {
+			return fmt.Errorf("rql: field %q of range %q is not a time field", b, name)
+		}
+		fs[i] = f.FieldMeta
+	}
+	start := p.fields[bounds[0]]
+	p.fields[name] = &Field{
+		FieldMeta: &FieldMeta{
+			Name:         name,
+			Column:       name,
+			Filterable:   true,
+			FilterOps:    map[string]bool{p.op(OVERLAPS): true},
+			AvailableOps: []string{p.op(OVERLAPS)},
+			Type:         start.Type,
+			Kind:         start.Kind,
+			Layout:       start.Layout,
+			Layouts:      start.Layouts,
+			Range:        fs,
+		},
+		ValidateFn: start.ValidateFn,
+		CovertFn:   start.CovertFn,
+	}
 	return nil
 }
 
@@ -417,7 +1207,11 @@ func (p *Parser) parseField(sf reflect.StructField) error {
 		},
 		CovertFn: valueFn,
 	}
-	layout := time.RFC3339
+	var (
+		allowedOps []string
+		bounds     [2]string
+		opColumns  = make(map[Op]string)
+	)
 	opts := strings.Split(sf.Tag.Get(p.TagName), ",")
 	for _, opt := range opts {
 		switch s := strings.TrimSpace(opt); {
@@ -425,28 +1219,92 @@ func (p *Parser) parseField(sf reflect.StructField) error {
 			f.Sortable = true
 		case s == "filter":
 			f.Filterable = true
+		case s == "search":
+			f.Searchable = true
+		case s == "likecast":
+			f.LikeCast = true
+		case s == "json":
+			f.JSON = true
+		case s == "group":
+			f.Groupable = true
+		case s == "select", s == "selectable":
+			f.Selectable = true
+		case s == "nonempty":
+			f.NonEmpty = true
+		case s == "nonblank":
+			f.NonEmpty = true
+			f.NonBlank = true
 		case strings.HasPrefix(opt, "column"):
 			f.Column = strings.TrimPrefix(opt, "column=")
+		case strings.Contains(opt, "=") && strings.HasSuffix(strings.SplitN(opt, "=", 2)[0], "column"):
+			// operator-specific columns, i.e. "eqcolumn=name_lower".
+			kv := strings.SplitN(opt, "=", 2)
+			opColumns[Op(strings.TrimSuffix(kv[0], "column"))] = kv[1]
 		case strings.HasPrefix(opt, "name"):
 			f.Name = strings.TrimPrefix(opt, "name=")
-		case strings.HasPrefix(opt, "layout"):
-			layout = strings.TrimPrefix(opt, "layout=")
-			// if it's one of the standard layouts, : RFC822 or Kitchen.
-			if ly, ok := layouts[layout]; ok {
-				layout = ly
+		case strings.HasPrefix(opt, "ops"):
+			allowedOps = strings.Split(strings.TrimPrefix(opt, "ops="), "|")
+		case strings.HasPrefix(opt, "op="):
+			f.DefaultOp = Op(strings.TrimPrefix(opt, "op="))
+			if !defaultStringOps[f.DefaultOp] {
+				return fmt.Errorf("rql: default op %q is not supported for field %q", f.DefaultOp, sf.Name)
+			}
+		case strings.HasPrefix(opt, "nulls"):
+			f.Nulls = Nulls("nulls" + strings.TrimPrefix(opt, "nulls="))
+			if _, ok := nullsFormat[f.Nulls]; !ok {
+				return fmt.Errorf("rql: nulls placement %q is not supported for field %q", opt, sf.Name)
+			}
+		case strings.HasPrefix(opt, "dir="):
+			dir, ok := sortDirs[strings.TrimPrefix(opt, "dir=")]
+			if !ok {
+				return fmt.Errorf("rql: sort direction %q is not supported for field %q", opt, sf.Name)
 			}
-			// test the layout on a value (on itself). however, some layouts are invalid
-			// time values for time.Parse, due to formats such as _ for space padding and
-			// Z for zone information.
-			v := strings.NewReplacer("_", " ", "Z", "+").Replace(layout)
-			if _, err := time.Parse(layout, v); err != nil {
-				return fmt.Errorf("rql: layout %q is not parsable: %v", layout, err)
+			f.Dir = dir
+		case strings.HasPrefix(opt, "collate"):
+			f.Collate = strings.TrimPrefix(opt, "collate=")
+		case strings.HasPrefix(opt, "via"):
+			f.Via = strings.TrimPrefix(opt, "via=")
+		case strings.HasPrefix(opt, "table"):
+			f.Table = strings.TrimPrefix(opt, "table=")
+		case strings.HasPrefix(opt, "join"):
+			f.Join = strings.TrimPrefix(opt, "join=")
+		case strings.HasPrefix(opt, "min="):
+			bounds[0] = strings.TrimPrefix(opt, "min=")
+		case strings.HasPrefix(opt, "max="):
+			bounds[1] = strings.TrimPrefix(opt, "max=")
+		case strings.HasPrefix(opt, "layout"):
+			// multiple layouts are separated by |, and tried in order: RFC3339|2006-01-02.
+			for _, layout := range strings.Split(strings.TrimPrefix(opt, "layout="), "|") {
+				// if it's one of the standard layouts, : RFC822 or Kitchen.
+				if ly, ok := layouts[layout]; ok {
+					layout = ly
+				}
+				// if it's a reference to the layouts registry, : @shortdate.
+				if strings.HasPrefix(layout, "@") {
+					ly, ok := p.Layouts[layout[1:]]
+					if !ok {
+						return fmt.Errorf("rql: layout %q is not registered in the config", layout)
+					}
+					layout = ly
+				}
+				// test the layout on a value (on itself). however, some layouts are invalid
+				// time values for time.Parse, due to formats such as _ for space padding and
+				// Z for zone information.
+				v := strings.NewReplacer("_", " ", "Z", "+").Replace(layout)
+				if _, err := time.Parse(layout, v); err != nil {
+					return fmt.Errorf("rql: layout %q is not parsable: %v", layout, err)
+				}
+				f.Layouts = append(f.Layouts, layout)
 			}
 		default:
 			p.Log("Ignoring unknown option %q in struct tag", opt)
 		}
 	}
-	f.Layout = layout
+	if len(f.Layouts) == 0 {
+		f.Layouts = []string{time.RFC3339}
+	}
+	f.Layout = f.Layouts[0]
+	f.timeFn = p.TimeFn
 
 	if f.Name == "" {
 		if p.NameFn != nil {
@@ -457,25 +1315,123 @@ func (p *Parser) parseField(sf reflect.StructField) error {
 	}
 
 	f.Type = indirect(sf.Type)
+	f.Kind = f.Type.Kind()
+	f.Nullable = isNullable(sf.Type)
+	if f.JSON && !isJSON(f.Type) {
+		return fmt.Errorf("rql: json option is not supported for field %q", sf.Name)
+	}
 	filterOps := p.Config.GetSupportedOps(f.FieldMeta)
-	if len(filterOps) == 0 {
+	// JSON fields are filtered by their paths, and may not support any operator themselves.
+	if len(filterOps) == 0 && !f.JSON {
 		return fmt.Errorf("rql: field type for %q is not supported", sf.Name)
 	}
-	f.CovertFn = p.Config.GetConverter(f.FieldMeta)
+	if f.Searchable && !isText(f.Type) {
+		return fmt.Errorf("rql: search option is not supported for field %q", sf.Name)
+	}
+	if f.Collate != "" && !isText(f.Type) {
+		return fmt.Errorf("rql: collate option is not supported for field %q", sf.Name)
+	}
+	if f.Dir != 0 && !f.Sortable {
+		return fmt.Errorf("rql: dir option is not supported for non-sortable field %q", sf.Name)
+	}
+	for i, b := range bounds {
+		if b == "" {
+			continue
+		}
+		v, err := f.parseBound(b)
+		if err != nil {
+			return fmt.Errorf("rql: %s option of field %q: %v", [...]string{"min", "max"}[i], sf.Name, err)
+		}
+		if i == 0 {
+			f.Min = v
+		} else {
+			f.Max = v
+		}
+	}
+	if f.LikeCast {
+		if isText(f.Type) {
+			return fmt.Errorf("rql: likecast option is not supported for field %q", sf.Name)
+		}
+		filterOps = append(filterOps, LIKE, ILIKE)
+	}
+	f.CovertFn = p.Config.GetConverter(f.FieldMeta)
 	f.ValidateFn = p.Config.GetValidator(f.FieldMeta)
 
 	for _, op := range filterOps {
 		f.FilterOps[p.op(op)] = true
 	}
+	// the default string op is applied only on string fields that support it.
+	switch text := isText(f.Type) && !isUnmarshaler(f.Type); {
+	case f.DefaultOp != "" && (!text || !f.FilterOps[p.op(f.DefaultOp)]):
+		return fmt.Errorf("rql: op option is not supported for field %q", sf.Name)
+	case f.DefaultOp == "" && text && f.FilterOps[p.op(p.DefaultStringOp)]:
+		f.DefaultOp = p.DefaultStringOp
+	}
+	if allowedOps != nil {
+		f.AllowedOps = make(map[string]bool, len(allowedOps))
+		for _, op := range allowedOps {
+			op = p.op(Op(strings.TrimSpace(op)))
+			if !f.FilterOps[op] {
+				return fmt.Errorf("rql: op %q is not supported by field %q", op, sf.Name)
+			}
+			f.AllowedOps[op] = true
+		}
+	}
+	for op, column := range opColumns {
+		if !f.FilterOps[p.op(op)] {
+			return fmt.Errorf("rql: op %q of %scolumn option is not supported by field %q", p.op(op), op, sf.Name)
+		}
+		if f.OpColumns == nil {
+			f.OpColumns = make(map[Op]string, len(opColumns))
+		}
+		f.OpColumns[op] = column
+	}
+	if f.Filterable {
+		for _, op := range filterOps {
+			if name := p.op(op); f.AllowedOps == nil || f.AllowedOps[name] {
+				f.AvailableOps = append(f.AvailableOps, name)
+			}
+		}
+	}
+	if p.NormalizeKeyFn != nil {
+		f.Name = p.NormalizeKeyFn(f.Name)
+		if _, ok := p.fields[f.Name]; ok {
+			return fmt.Errorf("rql: field name %q of %q collides with another field after normalization", f.Name, sf.Name)
+		}
+	}
 	p.fields[f.Name] = f
+	if f.Selectable {
+		p.selectable = append(p.selectable, f.FieldMeta)
+	}
 	return nil
 }
 
 type parseState struct {
-	*Parser                     // reference of the parser config
-	*bytes.Buffer               // query builder
-	values        []interface{} // query values
-	argN          int           // current arg counter
+	*Parser                       // reference of the parser config
+	*bytes.Buffer                 // query builder
+	values        []interface{}   // query values
+	argN          int             // current arg counter
+	joins         []string        // join clauses of the referenced fields
+	usedOps       map[string][]Op // operators applied on each column
+	names         []string        // parameter names, used only for named parameters
+	lenient       bool            // collect unknown keys instead of failing, used by ValidateAgainst
+	missing       []string        // unknown keys that were collected in lenient mode
+	sortKeys      []sortKey       // fields of the sort clause, used for the cursor expression
+	root          *FilterNode     // filter tree of the query
+	depth         int             // current nesting level of the logical operators
+	path          []string        // keys of the current nesting level, used for reporting the exceeded path
+	conds         int             // number of predicates in the filter
+	trusted       bool            // building the DefaultFilter, which is exempt from the filter limits
+	comments      bool            // annotate the rendered predicates, used for the debug expression
+	errs          []*ParseError   // field errors that were collected, used only if CollectErrors is set
+	ctx           context.Context // context of the parse call, checked before descending into logical groups
+	args          []interface{}   // scratch arguments of the formatted expressions, reused between calls
+}
+
+// sortKey is a field of the sort clause and its direction.
+type sortKey struct {
+	name string
+	dir  Direction
 }
 
 var parseStatePool sync.Pool
@@ -495,124 +1451,830 @@ func (p *Parser) newParseState() (ps *parseState) {
 	ps.values = make([]interface{}, 0, 8)
 	ps.Parser = p
 	ps.argN = 0
+	ps.joins = nil
+	ps.usedOps = make(map[string][]Op)
+	// scratch slices that don't escape the parse state are truncated and reused.
+	ps.names = ps.names[:0]
+	ps.lenient = false
+	ps.missing = nil
+	ps.sortKeys = ps.sortKeys[:0]
+	ps.root = nil
+	ps.depth = 0
+	ps.path = ps.path[:0]
+	ps.conds = 0
+	ps.trusted = false
+	ps.comments = false
+	ps.errs = nil
+	ps.ctx = nil
 	return
 }
 
-// sort build the sort clause.
-func (p *Parser) sort(fields []string) string {
-	sortParams := make([]string, len(fields))
-	for i, field := range fields {
-		expect(field != "", "sort field can not be empty")
-
-		var orderBy string
-		f0 := field[0]
-		if f0 == byte(ASC) || f0 == byte(DESC) {
-			orderBy = p.GetDBDir(Direction(f0))
-			field = field[1:]
+// sort build the sort clause. The secondary fields and then the configured SortTiebreaker fields
+// are appended to the given fields, unless they are already sorted.
+func (p *parseState) sort(fields []string, secondary ...string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	sortParams := make([]string, 0, len(fields)+len(secondary)+len(p.SortTiebreaker))
+	sorted := make(map[string]bool, len(fields))
+	p.sortKeys = p.sortKeys[:0]
+	for _, field := range fields {
+		name, dir, exp := p.sortTerm(field)
+		if sorted[name] {
+			expect(p.DedupSort, "sort field %q is repeated", name)
+			continue
 		}
-
-		expect(p.fields[field] != nil, "unrecognized key %q for sorting", field)
-		expect(p.fields[field].Sortable, "field %q is not sortable", field)
-		colName := p.colName(field)
-		if orderBy != "" {
-			colName += " " + orderBy
+		sorted[name] = true
+		sortParams = append(sortParams, exp)
+		p.sortKeys = append(p.sortKeys, sortKey{name, dir})
+	}
+	for _, field := range append(secondary[:len(secondary):len(secondary)], p.SortTiebreaker...) {
+		if name, dir, exp := p.sortTerm(field); !sorted[name] {
+			sorted[name] = true
+			sortParams = append(sortParams, exp)
+			p.sortKeys = append(p.sortKeys, sortKey{name, dir})
 		}
-		sortParams[i] = colName
 	}
 	return strings.Join(sortParams, ", ")
 }
 
-func (p *parseState) and(f map[string]interface{}) {
-	var i int
-	for k, v := range f {
-		if i > 0 {
-			p.WriteString(" AND ")
-		}
-		switch {
-		case k == p.op(OR):
-			terms, ok := v.([]interface{})
-			expect(ok, "$or must be type array")
-			p.relOp(OR, terms)
-		case k == p.op(AND):
-			terms, ok := v.([]interface{})
-			expect(ok, "$and must be type array")
-			p.relOp(AND, terms)
-		case p.fields[k] != nil:
-			f := p.fields[k]
-			expect(f.Filterable, "field %q is not filterable", k)
-			p.field(f, v)
-		default:
-			expect(false, "unrecognized key %q for filtering", k)
+// sortTerm build the sort expression of the given field, and returns it with the field name and its direction.
+// for example: "-created_at nullslast" returns "created_at", DESC and "created_at desc NULLS LAST".
+func (p *parseState) sortTerm(field string) (string, Direction, string) {
+	field = p.key(field)
+	expect(field != "", "sort field can not be empty")
+
+	var orderBy string
+	dir := ASC
+	f0 := field[0]
+	if f0 == byte(ASC) || f0 == byte(DESC) {
+		dir = Direction(f0)
+		orderBy = p.GetDBDir(dir)
+		field = field[1:]
+	}
+	var nulls Nulls
+	if j := strings.IndexByte(field, ' '); j != -1 {
+		nulls = Nulls(strings.TrimSpace(field[j+1:]))
+		field = field[:j]
+		_, ok := nullsFormat[nulls]
+		expect(ok, "unrecognized null placement %q for sorting field %q", nulls, field)
+	}
+
+	f := p.fields[field]
+	if f == nil && p.lenient {
+		p.miss(field)
+		return field, dir, ""
+	}
+	expectField(f != nil, ErrUnknownField, field, nil, "unrecognized key %q for sorting", field)
+	expectField(f.Sortable, ErrFieldNotAllowed, field, nil, "field %q is not sortable", field)
+	if orderBy == "" && f.Dir != 0 {
+		dir = f.Dir
+		orderBy = p.GetDBDir(dir)
+	}
+	p.join(f.FieldMeta)
+	colName := p.column(f.FieldMeta)
+	if f.Collate != "" {
+		colName += " " + p.GetDBCollate(f.Collate)
+	}
+	if orderBy != "" {
+		colName += " " + orderBy
+	}
+	if nulls == "" {
+		nulls = f.Nulls
+	}
+	if nulls == "" && f.Nullable {
+		nulls = p.SortNulls
+	}
+	if nulls != "" {
+		if placement := p.GetDBNulls(nulls); placement != "" {
+			colName += " " + placement
 		}
-		i++
 	}
+	return field, dir, colName
 }
 
-func (p *parseState) relOp(op Op, terms []interface{}) {
-	var i int
-	if len(terms) > 1 {
+// cursor build the keyset pagination expression for the given cursor. The cursor fields must match the
+// fields of the sort clause, and each field is compared according to its sort direction. For example,
+// for the sort clause "age desc, id" it returns "(age < ? OR (age = ? AND id > ?))".
+func (p *parseState) cursor(after map[string]interface{}) {
+	expect(len(p.sortKeys) > 0, "cursor requires a sort expression")
+	values := make(map[string]interface{}, len(after))
+	for k, v := range after {
+		k = p.key(k)
+		f := p.fields[k]
+		expectField(f != nil, ErrUnknownField, k, nil, "unrecognized key %q for cursor", k)
+		expectField(f.Sortable, ErrFieldNotAllowed, k, nil, "field %q is not sortable", k)
+		values[k] = v
+	}
+	expect(len(values) == len(p.sortKeys), "cursor fields must match the sort fields")
+	if len(p.sortKeys) > 1 {
 		p.WriteByte('(')
 	}
-	for _, t := range terms {
+	for i, sk := range p.sortKeys {
+		_, ok := values[sk.name]
+		expect(ok, "cursor field %q is missing, cursor fields must match the sort fields", sk.name)
 		if i > 0 {
-			p.WriteByte(' ')
-			op, _ := p.GetDBStatement(op, nil) // AND
-			p.WriteString(op)
-			p.WriteByte(' ')
+			p.WriteString(" " + p.OrKeyword + " (")
+		}
+		for _, prev := range p.sortKeys[:i] {
+			p.cursorOp(prev.name, EQ, values[prev.name])
+			p.WriteString(" " + p.AndKeyword + " ")
+		}
+		op := GT
+		if sk.dir == DESC {
+			op = LT
+		}
+		p.cursorOp(sk.name, op, values[sk.name])
+		if i > 0 {
+			p.WriteByte(')')
 		}
-		mt, ok := t.(map[string]interface{})
-		expect(ok, "expressions for $%s operator must be type object", op)
-		p.and(mt)
-		i++
 	}
-	if len(terms) > 1 {
+	if len(p.sortKeys) > 1 {
 		p.WriteByte(')')
 	}
 }
 
-func (p *parseState) field(f *Field, v interface{}) {
+// cursorOp validates the given cursor value of the field, and writes its comparison.
+func (p *parseState) cursorOp(name string, op Op, v interface{}) {
+	f := p.fields[name]
+	expect(f.ValidateFn != nil, "field %q can not be used in a cursor", name)
+	p.values = append(p.values, p.value(f, op, v))
+	p.WriteString(p.fmtOp(f.FieldMeta, op))
+}
+
+// group build the group by clause.
+func (p *parseState) group(fields []string) string {
+	cols := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = p.key(field)
+		f := p.fields[field]
+		if f == nil && p.lenient {
+			p.miss(field)
+			continue
+		}
+		expectField(f != nil, ErrUnknownField, field, nil, "unrecognized key %q for grouping", field)
+		expectField(f.Groupable, ErrFieldNotAllowed, field, nil, "field %q is not groupable", field)
+		p.join(f.FieldMeta)
+		cols = append(cols, p.column(f.FieldMeta))
+	}
+	return strings.Join(cols, ", ")
+}
+
+// and builds the AND group of the given filter object, in the sorted order of its keys. for example: "age > ? AND name = ?".
+func (p *parseState) and(f map[string]interface{}) *FilterNode {
+	n := &FilterNode{Op: AND, bare: true}
+	if len(f) == 1 {
+		for k, v := range f {
+			p.term(n, k, v)
+		}
+		return n
+	}
+	// the keys are visited in sorted order, so identical queries produce identical expressions and arguments.
+	for _, k := range sortedKeys(f) {
+		p.term(n, k, f[k])
+	}
+	return n
+}
+
+// sortedKeys returns the keys of the given object in sorted order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	if len(keys) > 1 {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// term adds the expression of the given key of a filter object to its AND group. i.e. a field, or a logical operator.
+func (p *parseState) term(n *FilterNode, k string, v interface{}) {
+	k = p.key(k)
+	switch {
+	case k == p.op(OR):
+		terms, ok := v.([]interface{})
+		expect(ok, "$or must be type array")
+		p.nest(k, func() { n.add(p.relOp(OR, terms)) })
+	case k == p.op(AND):
+		terms, ok := v.([]interface{})
+		expect(ok, "$and must be type array")
+		p.nest(k, func() { n.add(p.relOp(AND, terms)) })
+	case k == p.op(NOT):
+		term, ok := v.(map[string]interface{})
+		expect(ok && len(term) > 0, "$not must be type object with at least one expression")
+		p.nest(k, func() { n.add(p.not(term)) })
+	case p.fields[k] != nil:
+		f := p.fields[k]
+		p.collect(func() {
+			expectField(f.Filterable, ErrFieldNotAllowed, k, v, "field %q is not filterable", k)
+			n.add(p.field(f, v))
+		})
+	case p.jsonField(k) != nil:
+		f := p.jsonField(k)
+		p.collect(func() {
+			expectField(f.Filterable, ErrFieldNotAllowed, k, v, "field %q is not filterable", k)
+			n.add(p.field(f, v))
+		})
+	case p.lenient:
+		p.miss(k)
+	default:
+		p.collect(func() {
+			expectField(false, ErrUnknownField, k, v, "unrecognized key %q for filtering", k)
+		})
+	}
+}
+
+// collect runs the given function, and if CollectErrors is set, collects its ParseError instead of failing.
+// The collected errors are raised together by raise.
+func (p *parseState) collect(fn func()) {
+	if !p.CollectErrors {
+		fn()
+		return
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*ParseError)
+			if !ok {
+				panic(e)
+			}
+			p.errs = append(p.errs, perr)
+		}
+	}()
+	fn()
+}
+
+// raise panics with the collected errors, if there are any. A single error is raised as is, and multiple
+// errors are raised as a ParseError that wraps them in ParseErrors, and has the details of the first one.
+func (p *parseState) raise() {
+	switch len(p.errs) {
+	case 0:
+		return
+	case 1:
+		panic(p.errs[0])
+	}
+	errs := make(ParseErrors, len(p.errs))
+	copy(errs, p.errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	panic(&ParseError{Code: errs[0].Code, Field: errs[0].Field, Value: errs[0].Value, msg: errs.Error(), err: errs})
+}
+
+// done panics with the error of the parse context, if it is done.
+func (p *parseState) done() {
+	if p.ctx != nil {
+		must(p.ctx.Err(), ErrCanceled, "", nil, "parsing canceled")
+	}
+}
+
+// nest runs the given function one nesting level deeper under the given key, and panics with the path of
+// the level if it exceeds MaxFilterDepth. All recursive expansions of the filter descend through it.
+func (p *parseState) nest(k string, fn func()) {
+	p.done()
+	p.depth++
+	p.path = append(p.path, k)
+	expectField(p.trusted || p.MaxFilterDepth == 0 || p.depth <= p.MaxFilterDepth, ErrLimitExceeded, k, nil, "filter must be nested at most %d levels deep, exceeded at %q", p.MaxFilterDepth, strings.Join(p.path, "."))
+	fn()
+	p.path = p.path[:len(p.path)-1]
+	p.depth--
+}
+
+// miss records the given unknown key, if it was not recorded before.
+func (p *parseState) miss(k string) {
+	for _, m := range p.missing {
+		if m == k {
+			return
+		}
+	}
+	p.missing = append(p.missing, k)
+}
+
+// not builds the negation of the given expressions. for example: "NOT (age > ?)".
+func (p *parseState) not(term map[string]interface{}) *FilterNode {
+	n := p.and(term)
+	if len(n.Children) == 0 {
+		return n
+	}
+	return &FilterNode{Op: NOT, Children: []*FilterNode{n}}
+}
+
+// relOp builds the group of the given logical operator. for example: "(age > ? OR name = ?)".
+func (p *parseState) relOp(op Op, terms []interface{}) *FilterNode {
+	if len(terms) == 0 && !p.AllowEmptyGroups {
+		expect(false, "%s must be a non-empty array", p.op(op))
+	}
+	n := &FilterNode{Op: op, Children: make([]*FilterNode, 0, len(terms))}
+	for i, t := range terms {
+		mt, ok := t.(map[string]interface{})
+		if !ok {
+			expect(false, "expressions for $%s operator must be type object", op)
+		}
+		p.path = append(p.path, strconv.Itoa(i))
+		n.add(p.and(mt))
+		p.path = p.path[:len(p.path)-1]
+	}
+	return n
+}
+
+// field builds the predicates of the given field. A field with more than one operator is
+// an AND group of its predicates. for example: "(age > ? AND age < ?)".
+func (p *parseState) field(f *Field, v interface{}) *FilterNode {
+	p.join(f.FieldMeta)
 	terms, ok := v.(map[string]interface{})
-	// default equality check.
+	// default equality check, or membership check for bare scalars on array fields.
 	if !ok {
+		// bare null values of nullable fields are IS NULL checks.
+		if v == nil && f.Nullable {
+			p.expectOp(f, p.op(EQ))
+			p.useOp(f, NULL)
+			return p.predicate(f, NULL)
+		}
 		op := EQ
-		err := f.ValidateFn(op, *f.FieldMeta, v)
-		must(err, "invalid datatype for field %q", f.Name)
-		p.WriteString(p.fmtOp(f.FieldMeta, op))
-		arg := f.CovertFn(op, *f.FieldMeta, v)
-		p.values = append(p.values, arg)
-	}
-	var i int
-	if len(terms) > 1 {
-		p.WriteByte('(')
-	}
-	for opName, opVal := range terms {
-		if i > 0 {
-			p.WriteString(" AND ")
+		if _, isList := v.([]interface{}); !isList && isArray(f.Type) {
+			op = p.ArrayScalarOp
+		} else if f.DefaultOp != "" {
+			op = f.DefaultOp
 		}
+		p.expectOp(f, p.op(op))
+		p.useOp(f, op)
+		value := p.value(f, op, v)
+		// bare string filters with a LIKE op are prefix matches.
+		if s, ok := value.(string); ok && (op == LIKE || op == ILIKE) {
+			if p.EscapeLike {
+				s = escapeLike(s)
+			}
+			value = s + "%"
+		}
+		return p.predicate(f, op, value)
+	}
+	n := &FilterNode{Op: AND, Children: make([]*FilterNode, 0, len(terms))}
+	for _, opName := range sortedKeys(terms) {
+		opVal := terms[opName]
 		op := Op(opName[1:])
-		expect(f.FilterOps[opName], "can not apply op %q on field %q", opName, f.Name)
-		must(f.ValidateFn(op, *f.FieldMeta, opVal), "invalid datatype or format for field %q", f.Name)
-		p.WriteString(p.fmtOp(f.FieldMeta, op))
-		arg := f.CovertFn(op, *f.FieldMeta, opVal)
-		p.values = append(p.values, arg)
-		i++
-	}
-	if len(terms) > 1 {
+		p.expectOp(f, opName)
+		// $contains on string fields is a substring match, and on array fields a containment check.
+		if op == CONTAINS && f.Type.Kind() == reflect.String {
+			op = INCLUDES
+		}
+		// null operands of equality checks on nullable fields are IS NULL and IS NOT NULL checks.
+		if opVal == nil && f.Nullable && (op == EQ || op == NEQ) {
+			op, opVal = NULL, op == EQ
+		}
+		p.useOp(f, op)
+		switch op {
+		case NULL:
+			must(validateBool(op, *f.FieldMeta, opVal), ErrTypeMismatch, f.Name, opVal, "invalid datatype for op %q on field %q", opName, f.Name)
+			if !opVal.(bool) {
+				op = NOTNULL
+			}
+			n.Children = append(n.Children, p.predicate(f, op))
+		case BETWEEN:
+			bounds, ok := opVal.([]interface{})
+			expectField(ok && len(bounds) == 2, ErrTypeMismatch, f.Name, opVal, "op %q on field %q expects an array of 2 elements", opName, f.Name)
+			n.Children = append(n.Children, p.predicate(f, op, p.value(f, op, bounds[0]), p.value(f, op, bounds[1])))
+		case OVERLAPS:
+			bounds, ok := opVal.(map[string]interface{})
+			expectField(ok && len(bounds) == 2 && bounds["start"] != nil && bounds["end"] != nil, ErrTypeMismatch, f.Name, opVal, "op %q on field %q expects an object with start and end", opName, f.Name)
+			n.Children = append(n.Children, p.predicate(f, op, p.value(f, op, bounds["start"]), p.value(f, op, bounds["end"])))
+		case INSUBNET:
+			must(validateSubnet(op, *f.FieldMeta, opVal), ErrTypeMismatch, f.Name, opVal, "invalid subnet for field %q", f.Name)
+			n.Children = append(n.Children, p.predicate(f, op, opVal))
+		case SIZE:
+			must(validateUInt(op, *f.FieldMeta, opVal), ErrTypeMismatch, f.Name, opVal, "invalid size for field %q", f.Name)
+			n.Children = append(n.Children, p.predicate(f, op, convertInt(op, *f.FieldMeta, opVal)))
+		default:
+			n.Children = append(n.Children, p.predicate(f, op, p.value(f, op, opVal)))
+		}
+	}
+	if len(n.Children) == 1 {
+		return n.Children[0]
+	}
+	return n
+}
+
+// predicate creates a leaf node that applies the given operator on the field.
+func (p *parseState) predicate(f *Field, op Op, values ...interface{}) *FilterNode {
+	meta := opField(f.FieldMeta, op)
+	// JSON path predicates are casted according to the type of their values.
+	if meta.Path != nil {
+		m := *meta
+		cast, ok := jsonCast(values)
+		expectField(ok, ErrTypeMismatch, f.Name, values, "values of field %q must be of the same type", f.Name)
+		m.Cast = cast
+		meta = &m
+	}
+	n := &FilterNode{Op: op, Field: meta, Values: values}
+	n.Exp = p.predicateExp(meta, op, len(n.Args()))
+	return n
+}
+
+// opField returns the field with the column of the given operator, if it has one.
+func opField(f *FieldMeta, op Op) *FieldMeta {
+	column, ok := f.OpColumns[op]
+	if !ok {
+		return f
+	}
+	m := *f
+	m.Column = column
+	return &m
+}
+
+// predicateExp returns the SQL expression of a predicate with n `?` placeholders. for example: "age > ?".
+func (p *parseState) predicateExp(f *FieldMeta, op Op, n int) string {
+	dbOp, fmtStr := p.GetDBStatement(op, f)
+	p.args = append(p.args[:0], p.operand(f), dbOp)
+	for i := 0; i < n; i++ {
+		p.args = append(p.args, "?")
+	}
+	return fmt.Sprintf(fmtStr, p.listArgs(op)...)
+}
+
+// listArgs returns the formatting arguments of the given operator. The placeholders of the list operators
+// are joined to a single argument, i.e. "?, ?" for the "%v %v (%v)" format.
+func (p *parseState) listArgs(op Op) []interface{} {
+	if (op != IN && op != NIN) || len(p.args) < 3 {
+		return p.args
+	}
+	placeholders := make([]string, len(p.args)-2)
+	for i, a := range p.args[2:] {
+		placeholders[i] = a.(string)
+	}
+	return append(p.args[:2], strings.Join(placeholders, ", "))
+}
+
+// render writes the given filter node, and appends its operands to the query values.
+func (p *parseState) render(n *FilterNode) {
+	switch {
+	case n.Field != nil:
+		values := n.Args()
+		p.values = append(p.values, values...)
+		p.WriteString(p.fmtOpN(n.Field, n.Op, len(values)))
+		if p.comments {
+			fmt.Fprintf(p, " /* field: %s op: %s */", n.Field.Name, n.Op)
+		}
+	case n.Op == NOT:
+		op, _ := p.GetDBStatement(NOT, nil)
+		p.WriteString(op)
+		p.WriteString(" (")
+		p.render(n.Children[0])
 		p.WriteByte(')')
+	default:
+		sep := p.AndKeyword
+		if n.Op == OR {
+			sep = p.OrKeyword
+		}
+		paren := !n.bare && len(n.Children) > 1
+		if paren {
+			p.WriteByte('(')
+		}
+		for i, c := range n.Children {
+			if i > 0 {
+				p.WriteByte(' ')
+				p.WriteString(sep)
+				p.WriteByte(' ')
+			}
+			p.render(c)
+		}
+		if paren {
+			p.WriteByte(')')
+		}
 	}
 }
 
+// debug renders the given filter node with comments, and restores the state of the rendered filter.
+// The filter is the first expression that is rendered, so its parameters are numbered from the start.
+func (p *parseState) debug(n *FilterNode) string {
+	values, argN, names := len(p.values), p.argN, len(p.names)
+	p.Reset()
+	p.argN = 0
+	p.comments = true
+	p.render(n)
+	p.comments = false
+	p.values, p.argN, p.names = p.values[:values], argN, p.names[:names]
+	return p.String()
+}
+
+// jsonPathSep separates the segments of JSON path keys, i.e. "metadata.tier". It does not depend on the
+// FieldSep, that separates the names of nested struct fields.
+const jsonPathSep = "."
+
+// jsonField returns the field of the given JSON path (i.e. "metadata.tier"), or nil if the key does not
+// start with the name of a JSON field.
+func (p *parseState) jsonField(k string) *Field {
+	for i := strings.LastIndex(k, jsonPathSep); i > 0; i = strings.LastIndex(k[:i], jsonPathSep) {
+		root := p.fields[k[:i]]
+		if root == nil || !root.JSON {
+			continue
+		}
+		path := strings.Split(k[i+len(jsonPathSep):], jsonPathSep)
+		for _, s := range path {
+			expect(isPathSegment(s), "invalid segment %q in JSON path %q", s, k)
+		}
+		ops := make(map[string]bool)
+		for _, op := range []Op{EQ, NEQ, LT, LTE, GT, GTE, BETWEEN, IN, NIN, NULL} {
+			ops[p.op(op)] = true
+		}
+		return &Field{
+			FieldMeta: &FieldMeta{
+				Name:       k,
+				Column:     root.Column,
+				Via:        root.Via,
+				Table:      root.Table,
+				Join:       root.Join,
+				Filterable: root.Filterable,
+				FilterOps:  ops,
+				Type:       reflect.TypeOf((*interface{})(nil)).Elem(),
+				Nullable:   true,
+				Path:       path,
+			},
+			ValidateFn: validateList(validateJSONValue),
+			CovertFn:   valueFn,
+		}
+	}
+	return nil
+}
+
+// expectOp panics if the given operator can not be applied on the field.
+func (p *parseState) expectOp(f *Field, opName string) {
+	if !f.FilterOps[opName] {
+		expectField(false, ErrInvalidOp, f.Name, nil, "can not apply op %q on field %q", opName, f.Name)
+	}
+	if f.AllowedOps != nil && !f.AllowedOps[opName] && !p.trusted {
+		expectField(false, ErrInvalidOp, f.Name, nil, "op %q is not allowed on field %q", opName, f.Name)
+	}
+}
+
+// useOp records that the given operator was applied on the field column.
+func (p *parseState) useOp(f *Field, op Op) {
+	if !p.trusted {
+		p.conds++
+		if p.MaxFilterConditions > 0 && p.conds > p.MaxFilterConditions {
+			expectField(false, ErrLimitExceeded, f.Name, nil, "filter must have at most %d conditions", p.MaxFilterConditions)
+		}
+	}
+	// range fields record the operator on the columns of their bounds.
+	fs := f.Range
+	if fs == nil {
+		fs = []*FieldMeta{f.FieldMeta}
+	}
+	for _, f := range fs {
+		col := p.baseColumn(opField(f, op))
+		p.usedOps[col] = append(p.usedOps[col], op)
+	}
+}
+
+// join adds the join clause of the given field, if it has one and it was not added before.
+func (p *parseState) join(f *FieldMeta) {
+	for _, b := range f.Range {
+		p.join(b)
+	}
+	if f.Join == "" {
+		return
+	}
+	for _, j := range p.joins {
+		if j == f.Join {
+			return
+		}
+	}
+	p.joins = append(p.joins, f.Join)
+}
+
+// value validates the given operand of the field, and returns its converted value.
+func (p *parseState) value(f *Field, op Op, v interface{}) interface{} {
+	if err := validateNonEmpty(f.FieldMeta, v); err != nil {
+		must(err, ErrInvalidValue, f.Name, v, "invalid value for field %q", f.Name)
+	}
+	// patterns of casted columns are strings, and are not converted to the field type.
+	if f.LikeCast && (op == LIKE || op == ILIKE) {
+		must(validateString(op, *f.FieldMeta, v), ErrTypeMismatch, f.Name, v, "invalid pattern for field %q", f.Name)
+		return v
+	}
+	// string booleans (i.e. "true") are accepted on bool fields if CoerceStringBool is set.
+	if s, ok := v.(string); ok && p.CoerceStringBool && isBool(f.Type) {
+		if b, err := strconv.ParseBool(s); err == nil {
+			v = b
+		}
+	}
+	err := f.ValidateFn(op, *f.FieldMeta, v)
+	// negative bounds of range comparisons on unsigned fields (e.g. "$gt": -1) are allowed by policy.
+	if errors.Is(err, ErrNegativeUint) && p.AllowNegativeUintBounds && (op == GT || op == GTE || op == LT || op == LTE) {
+		err = nil
+	}
+	if err != nil {
+		must(err, ErrTypeMismatch, f.Name, v, "invalid datatype or format for field %q", f.Name)
+	}
+	raw := v
+	v = f.CovertFn(op, *f.FieldMeta, v)
+	if s, ok := v.(string); ok {
+		switch op {
+		case STARTSWITH:
+			v = escapeLike(s) + "%"
+		case ENDSWITH:
+			v = "%" + escapeLike(s)
+		case INCLUDES:
+			v = "%" + escapeLike(s) + "%"
+		}
+	}
+	if f.Min != nil || f.Max != nil {
+		vs, ok := v.([]interface{})
+		if !ok || !isListOp(op) {
+			vs = []interface{}{v}
+		}
+		for i := range vs {
+			must(f.checkBounds(vs[i]), ErrInvalidValue, f.Name, raw, "invalid value for field %q", f.Name)
+		}
+	}
+	if p.ValueFn == nil {
+		return v
+	}
+	// the transformer is applied on each element of list operands.
+	if vs, ok := v.([]interface{}); ok && isListOp(op) {
+		for i := range vs {
+			vs[i] = p.transform(f, vs[i])
+		}
+		return vs
+	}
+	return p.transform(f, v)
+}
+
+// transform applies the configured ValueFn on the given converted value.
+func (p *parseState) transform(f *Field, v interface{}) interface{} {
+	tv, err := p.ValueFn(f.FieldMeta, v)
+	must(err, ErrInvalidValue, f.Name, v, "invalid value for field %q", f.Name)
+	return tv
+}
+
 // fmtOp create a string for the operation with a placeholder.
 // for example: "name = ?", or "age >= ?".
 func (p *parseState) fmtOp(f *FieldMeta, op Op) string {
-	param := p.ParamSymbol
-	if p.PositionalParams {
-		param = fmt.Sprintf("%s%d", p.ParamSymbol, p.argN+p.ParamOffset)
-	}
-	p.argN++
+	return p.fmtOpN(f, op, 1)
+}
 
+// fmtOpN create a string for the operation with n placeholders. for example:
+// "deleted_at IS NULL" (0), "age = ?" (1), or "age BETWEEN ? AND ?" (2).
+func (p *parseState) fmtOpN(f *FieldMeta, op Op, n int) string {
 	dbOp, fmtStr := p.Config.GetDBStatement(op, f)
-	return fmt.Sprintf(fmtStr, p.colName(f.Name), dbOp, param)
+	p.args = append(p.args[:0], p.operand(f), dbOp)
+	// the values of the placeholders were appended to the query values before formatting.
+	var values []interface{}
+	if p.reuseParams() {
+		values = append(values, p.values[len(p.values)-n:]...)
+		p.values = p.values[:len(p.values)-n]
+	}
+	for i := 0; i < n; i++ {
+		param := p.ParamSymbol
+		switch {
+		case p.reuseParams():
+			param = fmt.Sprintf("%s%d", p.ParamSymbol, p.reuse(values[i])+p.ParamOffset)
+		case p.NamedParams:
+			name := fmt.Sprintf("%s_%d", paramName(p.baseColumn(f)), p.argN+p.ParamOffset)
+			p.names = append(p.names, name)
+			param = p.NamedParamSymbol + name
+		case p.PositionalParams:
+			param = fmt.Sprintf("%s%d", p.ParamSymbol, p.argN+p.ParamOffset)
+		}
+		p.argN++
+		p.args = append(p.args, param)
+	}
+	return fmt.Sprintf(fmtStr, p.listArgs(op)...)
+}
+
+// operand returns the column of the given field in the filter expression. for example: "age", or
+// "starts_at, ends_at" for range fields.
+func (p *parseState) operand(f *FieldMeta) string {
+	if f.Path != nil {
+		dbOp, fmtStr := p.GetDBStatement(JSONPATH, f)
+		return fmt.Sprintf(fmtStr, p.column(f), dbOp, jsonPath(f.Path))
+	}
+	if f.Range == nil {
+		return p.column(f)
+	}
+	cols := make([]string, len(f.Range))
+	for i, b := range f.Range {
+		cols[i] = p.column(b)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// reuseParams reports whether identical values share the same positional parameter.
+func (p *parseState) reuseParams() bool {
+	return p.ReuseParams && p.PositionalParams && !p.NamedParams
+}
+
+// reuse returns the index of the given value in the query values. The value is appended to
+// the query values if it does not exist.
+func (p *parseState) reuse(v interface{}) int {
+	for i := range p.values {
+		if reflect.DeepEqual(p.values[i], v) {
+			return i
+		}
+	}
+	p.values = append(p.values, v)
+	return len(p.values) - 1
+}
+
+// paramName replaces the characters of the given column that are not valid in a named parameter with "_".
+// for example: "addresses.city" will be changed to "addresses_city".
+func paramName(col string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, col)
+}
+
+// column returns the database column of the given field, qualified with its table (or the
+// configured TablePrefix) if it has one. for example: "users.name". Fields with an expression
+// return it as-is.
+func (p *Parser) column(f *FieldMeta) string {
+	if f.Expr != "" {
+		return f.Expr
+	}
+	table := f.Table
+	if table == "" {
+		table = p.TablePrefix
+	}
+	return p.qualify(table, p.baseColumn(f))
+}
+
+// baseColumn returns the unqualified database column of the given field. i.e. the "via" column
+// if the field is redirected to a denormalized column, or its "column" otherwise.
+func (p *Parser) baseColumn(f *FieldMeta) string {
+	if f.Via != "" {
+		return f.Via
+	}
+	return p.colName(f.Column)
+}
+
+// qualify prefixes the given column with the schema and the table name, unless the column is
+// already qualified, and quotes its identifiers if the parser was configured to.
+func (p *Parser) qualify(table, col string) string {
+	if table != "" && !strings.Contains(col, ".") {
+		if p.Schema != "" {
+			table = p.Schema + "." + table
+		}
+		col = table + "." + col
+	}
+	return p.quote(col)
+}
+
+// quote quotes each segment of the given identifier using the QuoteIdent function.
+// for example: "events.name" will be changed to `"events"."name"`.
+func (p *Parser) quote(ident string) string {
+	if p.QuoteIdent == nil {
+		return ident
+	}
+	parts := strings.Split(ident, ".")
+	for i := range parts {
+		parts[i] = p.QuoteIdent(parts[i])
+	}
+	return strings.Join(parts, ".")
+}
+
+// selectExp builds the select clause from the columns of the given fields. Each field must exist in
+// the model, and be selectable if the model has fields with the "select" option.
+func (p *Parser) selectExp(fields []string) string {
+	var wildcard bool
+	for _, field := range fields {
+		wildcard = wildcard || field == "*"
+	}
+	expect(!wildcard || !p.DenySelectWildcard, "select wildcard is not allowed")
+	if p.ExpandSelect && (len(fields) == 0 || wildcard) {
+		expect(len(fields) <= 1, "select wildcard can not be combined with other fields")
+		cols := make([]string, len(p.selectable))
+		for i, f := range p.selectable {
+			cols[i] = p.selectColumn(f)
+		}
+		return strings.Join(cols, ", ")
+	}
+	cols := make([]string, len(fields))
+	for i, field := range fields {
+		if field == "*" {
+			cols[i] = field
+			continue
+		}
+		f, ok := p.fields[field]
+		expectField(ok, ErrUnknownField, field, nil, "unrecognized key %q for selecting", field)
+		// if the model declares selectable fields, the other fields can not be selected explicitly.
+		expectField(f.Selectable || len(p.selectable) == 0, ErrFieldNotAllowed, field, nil, "field %q is not selectable", field)
+		cols[i] = p.selectColumn(f.FieldMeta)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// selectColumn returns the column of the given field in the select clause. Expressions are aliased
+// with the field column, i.e. "(first_name || ' ' || last_name) AS full_name".
+func (p *Parser) selectColumn(f *FieldMeta) string {
+	if f.Expr != "" {
+		return f.Expr + " AS " + p.quote(p.colName(f.Column))
+	}
+	return p.column(f)
+}
+
+// DoubleQuote quotes the given identifier with double quotes, as defined by the SQL standard (i.e. Postgres).
+// Double quotes within the identifier are escaped by doubling them.
+func DoubleQuote(ident string) string {
+	return `"` + strings.Replace(ident, `"`, `""`, -1) + `"`
 }
 
 // colName formats the query field to database column name in cases the user configured a custom
@@ -625,23 +2287,145 @@ func (p *Parser) colName(field string) string {
 	return field
 }
 
+// key returns the given incoming key trimmed and normalized, if the parser was configured
+// with TrimKeys and NormalizeKeyFn.
+func (p *Parser) key(k string) string {
+	if p.TrimKeys {
+		k = strings.TrimSpace(k)
+	}
+	if p.NormalizeKeyFn != nil {
+		k = p.NormalizeKeyFn(k)
+	}
+	return k
+}
+
+// keys is like key, but for a list of incoming keys.
+func (p *Parser) keys(ks []string) []string {
+	if !p.TrimKeys && p.NormalizeKeyFn == nil {
+		return ks
+	}
+	trimmed := make([]string, len(ks))
+	for i, k := range ks {
+		trimmed[i] = p.key(k)
+	}
+	return trimmed
+}
+
 func (p *Parser) op(op Op) string {
 	return p.OpPrefix + string(op)
 }
 
-// expect panic if the condition is false.
+// expect panic if the condition is false. Since its arguments are boxed even if the condition
+// holds, the hot paths of the parser check the condition before calling it.
 func expect(cond bool, msg string, args ...interface{}) {
 	if !cond {
-		panic(&ParseError{fmt.Sprintf(msg, args...)})
+		panic(&ParseError{Code: ErrInvalidQuery, msg: fmt.Sprintf(msg, args...)})
+	}
+}
+
+// expectField is like expect, but the error has the given code, and refers to the given field and its value.
+func expectField(cond bool, code ErrorCode, field string, value interface{}, msg string, args ...interface{}) {
+	if !cond {
+		panic(&ParseError{Code: code, Field: field, Value: value, msg: fmt.Sprintf(msg, args...)})
 	}
 }
 
-// must panics if the error is not nil.
-func must(err error, msg string, args ...interface{}) {
+// must panics if the error is not nil. The error has the given code, and refers to the given field and its value.
+func must(err error, code ErrorCode, field string, value interface{}, msg string, args ...interface{}) {
 	if err != nil {
 		args = append(args, err)
-		panic(&ParseError{fmt.Sprintf(msg+": %s", args...)})
+		panic(&ParseError{Code: code, Field: field, Value: value, msg: fmt.Sprintf(msg+": %s", args...), err: err})
+	}
+}
+
+// isNullable reports whether the given type can hold a NULL value. i.e. a pointer or a `sql.Null*` type.
+func isNullable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		return true
 	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	switch reflect.Zero(t).Interface().(type) {
+	case sql.NullBool, sql.NullString, sql.NullInt64, sql.NullFloat64, sql.NullTime:
+		return true
+	default:
+		return false
+	}
+}
+
+// isUnmarshaler reports whether the given type (or a pointer to it) implements encoding.TextUnmarshaler or
+// json.Unmarshaler, and it is not a time type (that is handled using its layout) or a JSON document. for example:
+// uuid.UUID, net.IP, decimal.Decimal or enum types.
+func isUnmarshaler(t reflect.Type) bool {
+	if t.ConvertibleTo(reflect.TypeOf(time.Time{})) {
+		return false
+	}
+	return reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) ||
+		reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) && !isJSON(t)
+}
+
+// isOrdered reports whether the given type has a Cmp method that compares it to another value of its type,
+// i.e. decimal.Decimal. Unmarshaler types that are ordered support the range comparison operators.
+func isOrdered(t reflect.Type) bool {
+	for _, t := range []reflect.Type{t, reflect.PtrTo(t)} {
+		m, ok := t.MethodByName("Cmp")
+		if ok && m.Type.NumIn() == 2 && indirect(m.Type.In(1)) == indirect(t) && m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Int {
+			return true
+		}
+	}
+	return false
+}
+
+// isIP reports whether the given type is an IP address. i.e. net.IP or netip.Addr.
+func isIP(t reflect.Type) bool {
+	return t == reflect.TypeOf(net.IP{}) || t.PkgPath() == "net/netip" && t.Name() == "Addr"
+}
+
+// isBig reports whether the given type is a big.Int or a big.Float.
+func isBig(t reflect.Type) bool {
+	return t == reflect.TypeOf(big.Int{}) || t == reflect.TypeOf(big.Float{})
+}
+
+// isArray reports whether the given type is an array or a slice, excluding []byte.
+func isArray(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8
+}
+
+// isJSON reports whether the given type can hold a JSON document. i.e. json.RawMessage, a map, a slice or an array.
+func isJSON(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return t == reflect.TypeOf(json.RawMessage{})
+}
+
+// isText reports whether the given type is a string or a sql.NullString.
+func isText(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t == reflect.TypeOf(sql.NullString{})
+}
+
+// isBool reports whether the given type is a bool or a sql.NullBool.
+func isBool(t reflect.Type) bool {
+	return t.Kind() == reflect.Bool || t == reflect.TypeOf(sql.NullBool{})
+}
+
+// isNumber reports whether the given type is an integer, a float, a sql.NullInt64 or a sql.NullFloat64.
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return t == reflect.TypeOf(sql.NullInt64{}) || t == reflect.TypeOf(sql.NullFloat64{})
+}
+
+// isTime reports whether the given type is a time type. i.e. time.Time, sql.NullTime, or a type that is
+// convertible to time.Time.
+func isTime(t reflect.Type) bool {
+	return t == reflect.TypeOf(sql.NullTime{}) || t.ConvertibleTo(reflect.TypeOf(time.Time{}))
 }
 
 // indirect returns the item at the end of indirection.
@@ -678,6 +2462,28 @@ func validateString(op Op, f FieldMeta, v interface{}) error {
 	return nil
 }
 
+// escapeLike escapes the wildcards of LIKE patterns (and the escape character itself) using a backslash.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// validate that string operands are not empty (or blank) for fields that were tagged with
+// the "nonempty" or "nonblank" options.
+func validateNonEmpty(f *FieldMeta, v interface{}) error {
+	s, ok := v.(string)
+	switch {
+	case !ok || !f.NonEmpty:
+		return nil
+	case s == "":
+		return errors.New("empty string is not allowed")
+	case f.NonBlank && strings.TrimSpace(s) == "":
+		return errors.New("blank string is not allowed")
+	}
+	return nil
+}
+
 // validate that the underlined element of given interface is a float.
 func validateFloat(op Op, f FieldMeta, v interface{}) error {
 	if _, ok := v.(float64); !ok {
@@ -698,42 +2504,280 @@ func validateInt(op Op, f FieldMeta, v interface{}) error {
 	return nil
 }
 
+// validate that the underlined element of given interface is a number or a numeric string that
+// can be parsed to the given big type.
+func validateBig(t reflect.Type) Validator {
+	return func(op Op, f FieldMeta, v interface{}) error {
+		_, err := parseBig(t, v)
+		return err
+	}
+}
+
+// validate that the underlined element of given interface is a subnet in CIDR notation, i.e. "10.0.0.0/8".
+func validateSubnet(op Op, f FieldMeta, v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return errorType(v, "string")
+	}
+	if _, _, err := net.ParseCIDR(s); err != nil {
+		return err
+	}
+	return nil
+}
+
 // validate that the underlined element of given interface is an int and greater than 0.
 func validateUInt(op Op, f FieldMeta, v interface{}) error {
 	if err := validateInt(op, f, v); err != nil {
 		return err
 	}
 	if v.(float64) < 0 {
-		return errors.New("not an unsigned integer")
+		return ErrNegativeUint
 	}
 	return nil
 }
 
-// validate that the underlined element of this interface is a "datetime" string.
-func validateTime(layout string) Validator {
+// validate that the underlined element of this interface is a "datetime" string
+// in one of the given layouts.
+func validateTime(layouts ...string) Validator {
+	return validateTimeFn(layoutsParser(layouts))
+}
+
+// validate that the underlined element of this interface is a string that is parsable by the given function.
+func validateTimeFn(parse func(string) (time.Time, error)) Validator {
 	return func(_ Op, _ FieldMeta, v interface{}) error {
 		s, ok := v.(string)
 		if !ok {
 			return errorType(v, "string")
 		}
-		_, err := time.Parse(layout, s)
+		_, err := parse(s)
+		return err
+	}
+}
+
+// validateUnmarshaler returns a validator that validates that the given value can be unmarshaled
+// to the given type. See unmarshal for more info.
+func validateUnmarshaler(t reflect.Type) Validator {
+	return func(op Op, f FieldMeta, v interface{}) error {
+		_, err := unmarshal(t, v)
 		return err
 	}
 }
 
+// unmarshal unmarshals the given value to a new value of the given type, and returns a pointer to it. Strings
+// are unmarshaled using the UnmarshalText method of the type if it exists. Other values (i.e. numbers) are
+// unmarshaled from their JSON encoding using the UnmarshalJSON method of the type.
+func unmarshal(t reflect.Type, v interface{}) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	if s, ok := v.(string); ok {
+		if u, ok := ptr.Interface().(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(s)); err != nil {
+				return ptr, fmt.Errorf("can not unmarshal %q to %v: %v", s, t, err)
+			}
+			return ptr, nil
+		}
+	}
+	u, ok := ptr.Interface().(json.Unmarshaler)
+	if !ok {
+		return ptr, errorType(v, "string")
+	}
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = u.UnmarshalJSON(b)
+	}
+	if err != nil {
+		return ptr, fmt.Errorf("can not unmarshal %v to %v: %v", v, t, err)
+	}
+	return ptr, nil
+}
+
+// validateList returns a validator that validates each one of the elements in the operand of
+// the list operators ($in, $nin and $contains) using the given validator.
+func validateList(fn Validator) Validator {
+	return func(op Op, f FieldMeta, v interface{}) error {
+		if !isListOp(op) {
+			return fn(op, f, v)
+		}
+		vs, ok := v.([]interface{})
+		if !ok {
+			return errorType(v, "array")
+		}
+		if len(vs) == 0 {
+			return errors.New("empty array")
+		}
+		for _, e := range vs {
+			if err := fn(op, f, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// convertList returns a converter that converts each one of the elements in the operand of
+// the list operators ($in, $nin and $contains) using the given converter.
+func convertList(fn Converter) Converter {
+	return func(op Op, f FieldMeta, v interface{}) interface{} {
+		if !isListOp(op) {
+			return fn(op, f, v)
+		}
+		vs := v.([]interface{})
+		converted := make([]interface{}, len(vs))
+		for i, e := range vs {
+			converted[i] = fn(op, f, e)
+		}
+		return converted
+	}
+}
+
+// isListOp reports whether the operand of the given operator is a list of values.
+func isListOp(op Op) bool {
+	return op == IN || op == NIN || op == CONTAINS
+}
+
+// convertUnmarshaler returns a converter that unmarshals the given value to the given type, if the type implements
+// driver.Valuer and can be bound as a query parameter (i.e. an enum type). Otherwise, the value is returned as is.
+func convertUnmarshaler(t reflect.Type) Converter {
+	if !reflect.PtrTo(t).Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem()) {
+		return valueFn
+	}
+	return func(op Op, f FieldMeta, v interface{}) interface{} {
+		ptr, _ := unmarshal(t, v)
+		if t.Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem()) {
+			return ptr.Elem().Interface()
+		}
+		return ptr.Interface()
+	}
+}
+
 // convert float to int.
 func convertInt(op Op, f FieldMeta, v interface{}) interface{} {
 	return int(v.(float64))
 }
 
+// convert number or numeric string to the string representation of the given big type.
+func convertBig(t reflect.Type) Converter {
+	return func(op Op, f FieldMeta, v interface{}) interface{} {
+		s, _ := parseBig(t, v)
+		return s
+	}
+}
+
+// parseBig parses the given number or numeric string to a big.Int or a big.Float, and returns its
+// string representation. Strings are not limited in precision, unlike JSON numbers that are decoded
+// as float64.
+func parseBig(t reflect.Type, v interface{}) (string, error) {
+	var s string
+	switch v := v.(type) {
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		s = v
+	default:
+		return "", errorType(v, "number")
+	}
+	if t == reflect.TypeOf(big.Int{}) {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return "", fmt.Errorf("can not parse %q to an integer", s)
+		}
+		return n.String(), nil
+	}
+	n, ok := new(big.Float).SetString(s)
+	if !ok || n.IsInf() {
+		return "", fmt.Errorf("can not parse %q to a number", s)
+	}
+	return s, nil
+}
+
 // convert string to time object.
-func convertTime(layout string) func(Op, FieldMeta, interface{}) interface{} {
+func convertTime(layouts ...string) func(Op, FieldMeta, interface{}) interface{} {
+	return convertTimeFn(layoutsParser(layouts))
+}
+
+// convert string to time object using the given function.
+func convertTimeFn(parse func(string) (time.Time, error)) func(Op, FieldMeta, interface{}) interface{} {
 	return func(_ Op, _ FieldMeta, v interface{}) interface{} {
-		t, _ := time.Parse(layout, v.(string))
+		t, _ := parse(v.(string))
 		return t
 	}
 }
 
+// parseTime parses the given value using the first matching layout. If none of the
+// layouts match, the error of the first one is returned.
+func parseTime(layouts []string, s string) (t time.Time, err error) {
+	for i, layout := range layouts {
+		pt, perr := time.Parse(layout, s)
+		if perr == nil {
+			return pt, nil
+		}
+		if i == 0 {
+			err = perr
+		}
+	}
+	return t, err
+}
+
+// validate that the underlined element of given interface is a JSON scalar. i.e. a string, a number or a bool.
+func validateJSONValue(op Op, f FieldMeta, v interface{}) error {
+	switch v.(type) {
+	case string, float64, bool:
+		return nil
+	default:
+		return errorType(v, "string, number or bool")
+	}
+}
+
+// jsonCast returns the cast of the value extracted by a JSON path, according to the type of the given
+// values. It reports false if the values do not have the same type.
+func jsonCast(values []interface{}) (cast string, ok bool) {
+	n := 0
+	for _, v := range values {
+		vs, isList := v.([]interface{})
+		if !isList {
+			vs = []interface{}{v}
+		}
+		for _, v := range vs {
+			c := ""
+			switch v.(type) {
+			case float64:
+				c = "numeric"
+			case bool:
+				c = "boolean"
+			}
+			if n > 0 && c != cast {
+				return "", false
+			}
+			cast = c
+			n++
+		}
+	}
+	return cast, true
+}
+
+// jsonPath returns the literal of the given JSON path for the extraction operator. for example: 0 for
+// an array index, 'tier' for an object key, or '{items,0}' for deeper paths.
+func jsonPath(path []string) string {
+	switch _, err := strconv.ParseUint(path[0], 10, 0); {
+	case len(path) > 1:
+		return "'{" + strings.Join(path, ",") + "}'"
+	case err == nil:
+		return path[0]
+	default:
+		return "'" + path[0] + "'"
+	}
+}
+
+// isPathSegment reports whether the given JSON path segment is an array index or an object key that
+// contains only letters, digits, '_' and '-'. Segments are written to the query as literals.
+func isPathSegment(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return s != ""
+}
+
 // nop converter.
 func valueFn(op Op, f FieldMeta, v interface{}) interface{} {
 	return v
@@ -80,7 +80,7 @@ func TestParse2(t *testing.T) {
 					map[string]interface{}{"key": map[string]interface{}{"someobject": "fdf"}},
 					"str",
 					[]interface{}{"1"},
-					[]interface{}{2},
+					2,
 					[]interface{}{1, 2}},
 				Sort: "",
 			},
@@ -103,12 +103,9 @@ func TestParse2(t *testing.T) {
 }
 
 var (
-	IN       = Op("in")
-	NIN      = Op("nin")
-	OVERLAP  = Op("overlap")
-	ALL      = Op("all")
-	CONTAINS = Op("contains")
-	EXISTS   = Op("exists")
+	OVERLAP = Op("overlap")
+	ALL     = Op("all")
+	EXISTS  = Op("exists")
 )
 
 func CustomGetSupportedOps(f *FieldMeta) []Op {
@@ -40,6 +40,10 @@ func easyjson4bc42f5bDecodeGithubComA8mRql(in *jlexer.Lexer, out *Query) {
 			out.Limit = int(in.Int())
 		case "offset":
 			out.Offset = int(in.Int())
+		case "page":
+			out.Page = int(in.Int())
+		case "pageSize":
+			out.PageSize = int(in.Int())
 		case "select":
 			if in.IsNull() {
 				in.Skip()
@@ -63,6 +67,8 @@ func easyjson4bc42f5bDecodeGithubComA8mRql(in *jlexer.Lexer, out *Query) {
 				}
 				in.Delim(']')
 			}
+		case "distinct":
+			out.Distinct = bool(in.Bool())
 		case "sort":
 			if in.IsNull() {
 				in.Skip()
@@ -86,6 +92,29 @@ func easyjson4bc42f5bDecodeGithubComA8mRql(in *jlexer.Lexer, out *Query) {
 				}
 				in.Delim(']')
 			}
+		case "group":
+			if in.IsNull() {
+				in.Skip()
+				out.Group = nil
+			} else {
+				in.Delim('[')
+				if out.Group == nil {
+					if !in.IsDelim(']') {
+						out.Group = make([]string, 0, 4)
+					} else {
+						out.Group = []string{}
+					}
+				} else {
+					out.Group = (out.Group)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v3 string
+					v3 = string(in.String())
+					out.Group = append(out.Group, v3)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		case "filter":
 			if in.IsNull() {
 				in.Skip()
@@ -99,19 +128,47 @@ func easyjson4bc42f5bDecodeGithubComA8mRql(in *jlexer.Lexer, out *Query) {
 				for !in.IsDelim('}') {
 					key := string(in.String())
 					in.WantColon()
-					var v3 interface{}
-					if m, ok := v3.(easyjson.Unmarshaler); ok {
+					var v4 interface{}
+					if m, ok := v4.(easyjson.Unmarshaler); ok {
 						m.UnmarshalEasyJSON(in)
-					} else if m, ok := v3.(json.Unmarshaler); ok {
+					} else if m, ok := v4.(json.Unmarshaler); ok {
 						_ = m.UnmarshalJSON(in.Raw())
 					} else {
-						v3 = in.Interface()
+						v4 = in.Interface()
 					}
-					(out.Filter)[key] = v3
+					(out.Filter)[key] = v4
 					in.WantComma()
 				}
 				in.Delim('}')
 			}
+		case "after":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.After = make(map[string]interface{})
+				} else {
+					out.After = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v5 interface{}
+					if m, ok := v5.(easyjson.Unmarshaler); ok {
+						m.UnmarshalEasyJSON(in)
+					} else if m, ok := v5.(json.Unmarshaler); ok {
+						_ = m.UnmarshalJSON(in.Raw())
+					} else {
+						v5 = in.Interface()
+					}
+					(out.After)[key] = v5
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "negate":
+			out.Negate = bool(in.Bool())
 		default:
 			in.AddError(&jlexer.LexerError{
 				Offset: in.GetPos(),
@@ -150,6 +207,26 @@ func easyjson4bc42f5bEncodeGithubComA8mRql(out *jwriter.Writer, in Query) {
 		}
 		out.Int(int(in.Offset))
 	}
+	if in.Page != 0 {
+		const prefix string = ",\"page\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Page))
+	}
+	if in.PageSize != 0 {
+		const prefix string = ",\"pageSize\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.PageSize))
+	}
 	if len(in.Select) != 0 {
 		const prefix string = ",\"select\":"
 		if first {
@@ -160,15 +237,25 @@ func easyjson4bc42f5bEncodeGithubComA8mRql(out *jwriter.Writer, in Query) {
 		}
 		{
 			out.RawByte('[')
-			for v4, v5 := range in.Select {
-				if v4 > 0 {
+			for v6, v7 := range in.Select {
+				if v6 > 0 {
 					out.RawByte(',')
 				}
-				out.String(string(v5))
+				out.String(string(v7))
 			}
 			out.RawByte(']')
 		}
 	}
+	if in.Distinct {
+		const prefix string = ",\"distinct\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Distinct))
+	}
 	if len(in.Sort) != 0 {
 		const prefix string = ",\"sort\":"
 		if first {
@@ -179,11 +266,30 @@ func easyjson4bc42f5bEncodeGithubComA8mRql(out *jwriter.Writer, in Query) {
 		}
 		{
 			out.RawByte('[')
-			for v6, v7 := range in.Sort {
-				if v6 > 0 {
+			for v8, v9 := range in.Sort {
+				if v8 > 0 {
 					out.RawByte(',')
 				}
-				out.String(string(v7))
+				out.String(string(v9))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Group) != 0 {
+		const prefix string = ",\"group\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v10, v11 := range in.Group {
+				if v10 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v11))
 			}
 			out.RawByte(']')
 		}
@@ -198,26 +304,66 @@ func easyjson4bc42f5bEncodeGithubComA8mRql(out *jwriter.Writer, in Query) {
 		}
 		{
 			out.RawByte('{')
-			v8First := true
-			for v8Name, v8Value := range in.Filter {
-				if v8First {
-					v8First = false
+			v12First := true
+			for v12Name, v12Value := range in.Filter {
+				if v12First {
+					v12First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v8Name))
+				out.String(string(v12Name))
 				out.RawByte(':')
-				if m, ok := v8Value.(easyjson.Marshaler); ok {
+				if m, ok := v12Value.(easyjson.Marshaler); ok {
 					m.MarshalEasyJSON(out)
-				} else if m, ok := v8Value.(json.Marshaler); ok {
+				} else if m, ok := v12Value.(json.Marshaler); ok {
 					out.Raw(m.MarshalJSON())
 				} else {
-					out.Raw(json.Marshal(v8Value))
+					out.Raw(json.Marshal(v12Value))
 				}
 			}
 			out.RawByte('}')
 		}
 	}
+	if len(in.After) != 0 {
+		const prefix string = ",\"after\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('{')
+			v13First := true
+			for v13Name, v13Value := range in.After {
+				if v13First {
+					v13First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v13Name))
+				out.RawByte(':')
+				if m, ok := v13Value.(easyjson.Marshaler); ok {
+					m.MarshalEasyJSON(out)
+				} else if m, ok := v13Value.(json.Marshaler); ok {
+					out.Raw(m.MarshalJSON())
+				} else {
+					out.Raw(json.Marshal(v13Value))
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	if in.Negate {
+		const prefix string = ",\"negate\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Negate))
+	}
 	out.RawByte('}')
 }
 
@@ -0,0 +1,67 @@
+//go:build go1.18
+// +build go1.18
+
+package rql
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParseNetIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    Config
+		input   []byte
+		wantErr bool
+		wantOut *Params
+	}{
+		{
+			name: "netip address",
+			conf: Config{
+				Model: new(struct {
+					Addr *netip.Addr `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"addr": { "$in": ["::1", "192.168.1.1"], "$insubnet": "192.168.0.0/16" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(addr IN (?, ?) AND addr << ?)",
+				FilterArgs: []interface{}{"::1", "192.168.1.1", "192.168.0.0/16"},
+			},
+		},
+		{
+			name: "netip address with invalid subnet",
+			conf: Config{
+				Model: new(struct {
+					Addr netip.Addr `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"addr": { "$insubnet": "10.0.0.1" }
+				}
+			}`),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.Log = t.Logf
+			p, err := NewParser(tt.conf)
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			out, err := p.Parse(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			assertParams(t, out, tt.wantOut)
+		})
+	}
+}
@@ -1,11 +1,21 @@
 package rql
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"net"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 	"unicode"
@@ -99,6 +109,151 @@ func TestInit(t *testing.T) {
 				}{}
 			})(),
 		},
+		{
+			name: "time format from registry",
+			model: new(struct {
+				CreatedAt time.Time `rql:"filter,layout=@shortdate"`
+			}),
+		},
+		{
+			name: "time format missing in registry",
+			model: new(struct {
+				CreatedAt time.Time `rql:"filter,layout=@longdate"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "multiple time layouts",
+			model: new(struct {
+				CreatedAt time.Time `rql:"filter,layout=Kitchen|@shortdate|2006-01-02 15:04"`
+			}),
+		},
+		{
+			name: "multiple time layouts with a missing one",
+			model: new(struct {
+				CreatedAt time.Time `rql:"filter,layout=RFC3339|@longdate"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "unsupported op option",
+			model: new(struct {
+				Name string `rql:"filter,op=gt"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "op option on non-string field",
+			model: new(struct {
+				Age int `rql:"filter,op=like"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "invalid null placement",
+			model: new(struct {
+				Age int `rql:"sort,nulls=middle"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "ops whitelist",
+			model: new(struct {
+				Name string `rql:"filter,ops=eq|neq"`
+			}),
+		},
+		{
+			name: "ops whitelist with unsupported op",
+			model: new(struct {
+				Age int `rql:"filter,ops=eq|like"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "collate option on non-text field",
+			model: new(struct {
+				Age int `rql:"sort,collate=en_US"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "search option on text fields",
+			model: new(struct {
+				Title string         `rql:"filter,search"`
+				Body  sql.NullString `rql:"filter,search"`
+			}),
+		},
+		{
+			name: "search option on non-text field",
+			model: new(struct {
+				Age int `rql:"filter,search"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "bounds on numeric and time fields",
+			model: new(struct {
+				Age       int       `rql:"filter,min=0,max=120"`
+				Score     float64   `rql:"filter,min=-1.5"`
+				CreatedAt time.Time `rql:"filter,max=2030-01-01T00:00:00Z"`
+			}),
+		},
+		{
+			name: "bounds on non-numeric field",
+			model: new(struct {
+				Name string `rql:"filter,min=0"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "invalid bound",
+			model: new(struct {
+				Age int `rql:"filter,max=a8m"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "json option on documents",
+			model: new(struct {
+				Scores   json.RawMessage        `rql:"filter,json"`
+				Tags     []string               `rql:"filter,json"`
+				Metadata map[string]interface{} `rql:"filter,json"`
+			}),
+		},
+		{
+			name: "json option on non-array field",
+			model: new(struct {
+				Name string `rql:"filter,json"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "invalid sort direction",
+			model: new(struct {
+				Age int `rql:"sort,dir=up"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "sort direction of non-sortable field",
+			model: new(struct {
+				Age int `rql:"filter,dir=desc"`
+			}),
+			wantErr: true,
+		},
+		{
+			name: "op columns",
+			model: new(struct {
+				Name string `rql:"filter,eqcolumn=name_lower,likecolumn=name_lower"`
+			}),
+		},
+		{
+			name: "op column of unsupported op",
+			model: new(struct {
+				Age int `rql:"filter,likecolumn=age_text"`
+			}),
+			wantErr: true,
+		},
 		{
 			name: "time format",
 			model: new(struct {
@@ -110,8 +265,9 @@ func TestInit(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := NewParser(Config{
-				Model: tt.model,
-				Log:   t.Logf,
+				Model:   tt.model,
+				Log:     t.Logf,
+				Layouts: map[string]string{"shortdate": "2006-01-02"},
 			})
 			if tt.wantErr != (err != nil) {
 				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
@@ -339,955 +495,6029 @@ func TestParse(t *testing.T) {
 			}`),
 			wantOut: &Params{
 				Limit:      25,
-				FilterExp:  "null_int64 = ? AND ptr_null_int64 = ? AND null_float64 = ? AND ptr_null_float64 = ? AND null_string = ? AND ptr_null_string = ?",
-				FilterArgs: []interface{}{1, 1, 1.0, 1.0, "", ""},
+				FilterExp:  "null_float64 = ? AND null_int64 = ? AND null_string = ? AND ptr_null_float64 = ? AND ptr_null_int64 = ? AND ptr_null_string = ?",
+				FilterArgs: []interface{}{1.0, 1, "", 1.0, 1, ""},
 			},
 		},
 		{
-			name: "time",
+			name: "sql null time",
 			conf: Config{
-				Model: func() interface{} {
-					type Date time.Time
-					return struct {
-						CreatedAt      time.Time  `rql:"filter"`
-						UpdatedAt      *time.Time `rql:"filter"`
-						SwaggerDate    Date       `rql:"filter"`
-						PtrSwaggerDate *Date      `rql:"filter"`
-					}{}
-				}(),
+				Model: struct {
+					ValidUntil    sql.NullTime  `rql:"filter"`
+					PtrValidUntil *sql.NullTime `rql:"filter,layout=2006-01-02"`
+				}{},
 				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"created_at": "2018-01-14T06:05:48.839Z",
-					"updated_at": "2018-01-14T06:05:48.839Z",
-					"swagger_date": "2018-01-14T06:05:48.839Z",
-					"ptr_swagger_date": "2018-01-14T06:05:48.839Z"
+					"valid_until": { "$gt": "2018-01-14T06:05:48.839Z" },
+					"ptr_valid_until": { "$between": ["2018-01-14", "2018-02-14"], "$null": false }
 				}
 			}`),
 			wantOut: &Params{
 				Limit:     25,
-				FilterExp: "created_at = ? AND updated_at = ? AND swagger_date = ? AND ptr_swagger_date = ?",
+				FilterExp: "valid_until > ? AND (ptr_valid_until BETWEEN ? AND ? AND ptr_valid_until IS NOT NULL)",
 				FilterArgs: []interface{}{
 					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
-					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
-					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
-					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
+					mustParseTime("2006-01-02", "2018-01-14"),
+					mustParseTime("2006-01-02", "2018-02-14"),
 				},
 			},
 		},
 		{
-			name: "valid operations",
+			name: "sql null time with invalid layout",
 			conf: Config{
-				Model: new(struct {
-					Age     int    `rql:"filter"`
-					Name    string `rql:"filter"`
-					Address string `rql:"filter"`
-				}),
-				DefaultLimit: 25,
+				Model: struct {
+					ValidUntil sql.NullTime `rql:"filter,layout=2006-01-02"`
+				}{},
 			},
 			input: []byte(`{
 				"filter": {
-					"age": { "$gt": 10 },
-					"name": { "$like": "%foo%" },
-					"$or": [
-						{ "address": { "$eq": "DC" } },
-						{ "address": { "$neq": "Marvel" } }
-					]
+					"valid_until": "2018-01-14T06:05:48.839Z"
 				}
 			}`),
-			wantOut: &Params{
-				Limit:      25,
-				FilterExp:  "age > ? AND name LIKE ? AND (address = ? OR address <> ?)",
-				FilterArgs: []interface{}{10, "%foo%", "DC", "Marvel"},
-			},
+			wantErr: true,
 		},
 		{
-			name: "custom operation prefix",
+			name: "null operator",
 			conf: Config{
-				Model: new(struct {
-					CreatedAt time.Time `rql:"filter"`
-					Work      struct {
-						Address string `rql:"filter"`
-						Salary  int    `rql:"filter"`
-					}
-				}),
-				OpPrefix:     "@",
-				FieldSep:     "#",
+				Model: struct {
+					Name       string         `rql:"filter"`
+					DeletedAt  *time.Time     `rql:"filter"`
+					NullString sql.NullString `rql:"filter"`
+					NullInt64  *sql.NullInt64 `rql:"filter"`
+				}{},
 				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"created_at": { "@gt": "2018-01-14T06:05:48.839Z" },
-					"work#address": { "@like": "%DC%" },
-					"@or": [
-						{
-							"work#salary": 100
-						},
-						{
-							"work#salary": { "@gte": 200, "@lte": 300 }
-						}
-					]
+					"name": "foo",
+					"deleted_at": { "$null": true },
+					"null_string": { "$null": false },
+					"null_int64": { "$null": false, "$gt": 1 }
 				}
 			}`),
 			wantOut: &Params{
 				Limit:      25,
-				FilterExp:  "created_at > ? AND work_address LIKE ? AND (work_salary = ? OR (work_salary >= ? AND work_salary <= ?))",
-				FilterArgs: []interface{}{mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"), "%DC%", 100, 200, 300},
+				FilterExp:  "name = ? AND deleted_at IS NULL AND null_string IS NOT NULL AND (null_int64 IS NOT NULL AND null_int64 > ?)",
+				FilterArgs: []interface{}{"foo", 1},
 			},
 		},
 		{
-			name: "sort",
+			name: "null operator on non-nullable field",
 			conf: Config{
 				Model: struct {
-					Age     int    `rql:"filter,sort"`
-					Name    string `rql:"filter,sort"`
-					Address struct {
-						Name string `rql:"filter,sort"`
-						ZIP  *struct {
-							Code int `rql:"filter,sort"`
-						}
-					}
+					Name string `rql:"filter"`
 				}{},
-				FieldSep:     ".",
-				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"address.zip.code": 100
-				},
-				"sort": ["address.name", "-address.zip.code", "+age"]
+					"name": { "$null": true }
+				}
 			}`),
-			wantOut: &Params{
-				Limit:      25,
-				FilterExp:  "address_zip_code = ?",
-				FilterArgs: []interface{}{100},
-				Sort:       "address_name, address_zip_code desc, age asc",
-			},
+			wantErr: true,
 		},
 		{
-			name: "sort with default field separator",
+			name: "null values",
 			conf: Config{
 				Model: struct {
-					Age     int    `rql:"filter,sort"`
-					Name    string `rql:"filter,sort"`
-					Address struct {
-						Name string `rql:"filter,sort"`
-						ZIP  *struct {
-							Code int `rql:"filter,sort"`
-						}
-					}
+					Name       string         `rql:"filter"`
+					DeletedAt  *time.Time     `rql:"filter"`
+					NullString sql.NullString `rql:"filter"`
+					NullInt64  *sql.NullInt64 `rql:"filter"`
 				}{},
 				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"address_zip_code": 100
-				},
-				"sort": ["address_name", "-address_zip_code", "+age"]
+					"name": "foo",
+					"deleted_at": null,
+					"null_string": { "$eq": null },
+					"null_int64": { "$neq": null }
+				}
 			}`),
 			wantOut: &Params{
 				Limit:      25,
-				FilterExp:  "address_zip_code = ?",
-				FilterArgs: []interface{}{100},
-				Sort:       "address_name, address_zip_code desc, age asc",
+				FilterExp:  "deleted_at IS NULL AND name = ? AND null_int64 IS NOT NULL AND null_string IS NULL",
+				FilterArgs: []interface{}{"foo"},
 			},
 		},
 		{
-			name: "sort with default sort field configured, and no sort in query",
+			name: "null value on non-nullable field",
 			conf: Config{
 				Model: struct {
-					Age     int    `rql:"filter,sort"`
-					Name    string `rql:"filter,sort"`
-					Address struct {
-						Name string `rql:"filter,sort"`
-						ZIP  *struct {
-							Code int `rql:"filter,sort"`
-						}
-					}
+					Name string `rql:"filter"`
 				}{},
-				DefaultLimit: 25,
-				DefaultSort:  []string{"-name"},
 			},
 			input: []byte(`{
 				"filter": {
-					"address_zip_code": 100
-				},
-				"sort": []
+					"name": null
+				}
 			}`),
-			wantOut: &Params{
-				Limit:      25,
-				FilterExp:  "address_zip_code = ?",
-				FilterArgs: []interface{}{100},
-				Sort:       "name desc",
-			},
+			wantErr: true,
 		},
 		{
-			name: "sort with default sort field configured, and sort specified in query",
+			name: "null operand on non-nullable field",
 			conf: Config{
 				Model: struct {
-					Age     int    `rql:"filter,sort"`
-					Name    string `rql:"filter,sort"`
-					Address struct {
-						Name string `rql:"filter,sort"`
-						ZIP  *struct {
-							Code int `rql:"filter,sort"`
-						}
-					}
+					Name string `rql:"filter"`
 				}{},
-				DefaultLimit: 25,
-				DefaultSort:  []string{"-name"},
 			},
 			input: []byte(`{
 				"filter": {
-					"address_zip_code": 100
-				},
-				"sort": ["-age"]
+					"name": { "$neq": null }
+				}
 			}`),
-			wantOut: &Params{
-				Limit:      25,
-				FilterExp:  "address_zip_code = ?",
-				FilterArgs: []interface{}{100},
-				Sort:       "age desc",
-			},
+			wantErr: true,
 		},
 		{
-			name: "select one",
+			name: "null operator with non-bool value",
 			conf: Config{
 				Model: struct {
-					Age  int    `rql:"filter,sort"`
-					Name string `rql:"filter,sort"`
+					DeletedAt *time.Time `rql:"filter"`
 				}{},
-				DefaultLimit: 25,
 			},
 			input: []byte(`{
-				"select": ["name"]
-			}`),
-			wantOut: &Params{
-				Limit:  25,
-				Select: "name",
-			},
+				"filter": {
+					"deleted_at": { "$null": "yes" }
+				}
+			}`),
+			wantErr: true,
 		},
 		{
-			name: "select many",
+			name: "nonempty accepts non-empty value",
 			conf: Config{
 				Model: struct {
-					Age  int    `rql:"filter,sort"`
-					Name string `rql:"filter,sort"`
+					Name string `rql:"filter,nonempty"`
 				}{},
 				DefaultLimit: 25,
 			},
 			input: []byte(`{
-				"select": ["name", "age"]
+				"filter": {
+					"name": { "$neq": "foo" }
+				}
 			}`),
 			wantOut: &Params{
-				Limit:  25,
-				Select: "name, age",
+				Limit:      25,
+				FilterExp:  "name <> ?",
+				FilterArgs: []interface{}{"foo"},
 			},
 		},
 		{
-			name: "custom column name",
+			name: "nonempty rejects empty value",
 			conf: Config{
 				Model: struct {
-					Name string `rql:"filter,column=full_name,sort"`
+					Name string `rql:"filter,nonempty"`
 				}{},
-				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"full_name": "a8m"
-				},
-				"sort": ["full_name"]
+					"name": ""
+				}
 			}`),
-			wantOut: &Params{
-				Limit:      25,
-				FilterExp:  "full_name = ?",
-				FilterArgs: []interface{}{"a8m"},
-				Sort:       "full_name",
-			},
+			wantErr: true,
 		},
 		{
-			name: "naming columns",
+			name: "nonblank rejects whitespace-only value",
 			conf: Config{
 				Model: struct {
-					ID           string `rql:"filter"`
-					FullName     string `rql:"filter"`
-					HTTPUrl      string `rql:"filter"`
-					NestedStruct struct {
-						UUID string `rql:"filter"`
-					}
+					Name string `rql:"filter,nonblank"`
 				}{},
-				FieldSep: ".",
 			},
 			input: []byte(`{
 				"filter": {
-					"id": "id",
-					"full_name": "full_name",
-					"http_url": "http_url",
-					"nested_struct.uuid": "uuid"
+					"name": { "$eq": "  " }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "time",
+			conf: Config{
+				Model: func() interface{} {
+					type Date time.Time
+					return struct {
+						CreatedAt      time.Time  `rql:"filter"`
+						UpdatedAt      *time.Time `rql:"filter"`
+						SwaggerDate    Date       `rql:"filter"`
+						PtrSwaggerDate *Date      `rql:"filter"`
+					}{}
+				}(),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": "2018-01-14T06:05:48.839Z",
+					"updated_at": "2018-01-14T06:05:48.839Z",
+					"swagger_date": "2018-01-14T06:05:48.839Z",
+					"ptr_swagger_date": "2018-01-14T06:05:48.839Z"
 				}
 			}`),
 			wantOut: &Params{
-				Limit:      25,
-				FilterExp:  "id = ? AND full_name = ? AND http_url = ? AND nested_struct_uuid = ?",
-				FilterArgs: []interface{}{"id", "full_name", "http_url", "uuid"},
+				Limit:     25,
+				FilterExp: "created_at = ? AND updated_at = ? AND swagger_date = ? AND ptr_swagger_date = ?",
+				FilterArgs: []interface{}{
+					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
+					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
+					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
+					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
+				},
 			},
 		},
 		{
-			name: "time unix layout",
+			name: "valid operations",
 			conf: Config{
 				Model: new(struct {
-					CreatedAt time.Time `rql:"filter,layout=UnixDate"`
+					Age     int    `rql:"filter"`
+					Name    string `rql:"filter"`
+					Address string `rql:"filter"`
 				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"created_at": { "$gt": "Thu May 23 09:30:06 IDT 2000" }
+					"age": { "$gt": 10 },
+					"name": { "$like": "%foo%" },
+					"$or": [
+						{ "address": { "$eq": "DC" } },
+						{ "address": { "$neq": "Marvel" } }
+					]
 				}
 			}`),
 			wantOut: &Params{
 				Limit:      25,
-				FilterExp:  "created_at > ?",
-				FilterArgs: []interface{}{mustParseTime(time.UnixDate, "Thu May 23 09:30:06 IDT 2000")},
+				FilterExp:  "age > ? AND name LIKE ? AND (address = ? OR address <> ?)",
+				FilterArgs: []interface{}{10, "%foo%", "DC", "Marvel"},
 			},
 		},
 		{
-			name: "time custom layout",
+			name: "between operator",
 			conf: Config{
 				Model: new(struct {
+					Age       int       `rql:"filter"`
+					Name      string    `rql:"filter"`
 					CreatedAt time.Time `rql:"filter,layout=2006-01-02 15:04"`
 				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"created_at": { "$gt": "2006-01-02 15:04" }
+					"$or": [
+						{ "age": { "$between": [10, 20] } },
+						{ "created_at": { "$between": ["2006-01-02 15:04", "2006-01-03 15:04"] } }
+					]
 				}
 			}`),
 			wantOut: &Params{
-				Limit:      25,
-				FilterExp:  "created_at > ?",
-				FilterArgs: []interface{}{mustParseTime("2006-01-02 15:04", "2006-01-02 15:04")},
+				Limit:     25,
+				FilterExp: "(age BETWEEN ? AND ? OR created_at BETWEEN ? AND ?)",
+				FilterArgs: []interface{}{
+					10, 20,
+					mustParseTime("2006-01-02 15:04", "2006-01-02 15:04"),
+					mustParseTime("2006-01-02 15:04", "2006-01-03 15:04"),
+				},
 			},
 		},
 		{
-			name: "mismatch time unix layout",
+			name: "between operator with positional params",
 			conf: Config{
 				Model: new(struct {
-					CreatedAt time.Time `rql:"filter,layout=UnixDate"`
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
 				}),
+				ParamSymbol:      "$",
+				PositionalParams: true,
+				DefaultLimit:     25,
 			},
 			input: []byte(`{
 				"filter": {
-					"created_at": { "$gt": "2006-01-02 15:04" }
+					"age": { "$between": [10, 20] }
 				}
 			}`),
-			wantErr: true,
+			wantOut: &Params{
+				Limit:            25,
+				FilterExp:        "age BETWEEN $1 AND $2",
+				FilterArgs:       []interface{}{10, 20},
+				ParamSymbol:      "$",
+				PositionalParams: true,
+			},
 		},
 		{
-			name: "mismatch int type 1",
+			name: "between operator expects 2 elements",
 			conf: Config{
-				Model: struct {
-					Age  int    `rql:"filter"`
-					Name string `rql:"filter"`
-				}{},
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
 				"filter": {
-					"age": "a8m",
-					"name": 10
+					"age": { "$between": [10, 20, 30] }
 				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "mismatch int type 2",
+			name: "between operator mismatch time layout",
 			conf: Config{
-				Model: struct {
-					Age int `rql:"filter"`
-				}{},
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter,layout=UnixDate"`
+				}),
 			},
 			input: []byte(`{
 				"filter": {
-					"age": 1.1
+					"created_at": { "$between": ["Thu May 23 09:30:06 IDT 2000", "2006-01-02 15:04"] }
 				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "mismatch string type",
+			name: "text unmarshaler types",
 			conf: Config{
-				Model: struct {
-					Name string `rql:"filter"`
-				}{},
+				Model: new(struct {
+					ID      testUUID    `rql:"filter,sort"`
+					OwnerID *testUUID   `rql:"filter"`
+					Version testVersion `rql:"filter"`
+				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"name": 10
-				}
+					"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+					"owner_id": { "$in": ["6ba7b811-9dad-11d1-80b4-00c04fd430c8"] },
+					"version": { "$neq": "v1.2" }
+				},
+				"sort": ["-id"]
 			}`),
-			wantErr: true,
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "id = ? AND owner_id IN (?) AND version <> ?",
+				FilterArgs: []interface{}{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "6ba7b811-9dad-11d1-80b4-00c04fd430c8", "v1.2"},
+				Sort:       "id desc",
+			},
 		},
 		{
-			name: "mismatch uint type 1",
+			name: "text unmarshaler non-struct types",
 			conf: Config{
-				Model: struct {
-					Age uint `rql:"filter"`
-				}{},
+				Model: new(struct {
+					Status    testStatus  `rql:"filter"`
+					Prev      *testStatus `rql:"filter"`
+					IP        net.IP      `rql:"filter"`
+					Tags      []testUUID  `rql:"filter"`
+					CreatedAt *time.Time  `rql:"filter"`
+				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"age": "a8m"
+					"status": "active",
+					"prev": { "$in": ["inactive", "active"] },
+					"ip": "10.0.0.1",
+					"tags": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+					"created_at": { "$gt": "2018-01-14T06:05:48.839Z" }
 				}
 			}`),
-			wantErr: true,
+			wantOut: &Params{
+				Limit:     25,
+				FilterExp: "status = ? AND prev IN (?, ?) AND ip = ? AND ? = ANY(tags) AND created_at > ?",
+				FilterArgs: []interface{}{
+					testStatus(1),
+					testStatus(2),
+					testStatus(1),
+					"10.0.0.1",
+					"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
+				},
+			},
 		},
 		{
-			name: "mismatch uint type 2",
+			name: "text unmarshaler non-struct type with invalid text",
 			conf: Config{
-				Model: struct {
-					Age uint `rql:"filter"`
-				}{},
+				Model: new(struct {
+					Status *testStatus `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
 				"filter": {
-					"age": -1
+					"status": "unknown"
 				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "mismatch time type 1",
+			name: "json unmarshaler type",
 			conf: Config{
-				Model: struct {
-					CreatedAt time.Time `rql:"filter"`
-				}{},
+				Model: new(struct {
+					Price testDecimal  `rql:"filter"`
+					Fee   *testDecimal `rql:"filter"`
+				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"created_at": "Sunday?"
+					"price": { "$gte": 10.5, "$lt": "20.25" },
+					"fee": { "$in": [1, "2.5"] }
 				}
 			}`),
-			wantErr: true,
+			wantOut: &Params{
+				Limit:     25,
+				FilterExp: "fee IN (?, ?) AND (price >= ? AND price < ?)",
+				FilterArgs: []interface{}{
+					testDecimal{"1"},
+					testDecimal{"2.5"},
+					testDecimal{"10.5"},
+					testDecimal{"20.25"},
+				},
+			},
 		},
 		{
-			name: "mismatch time type 2",
+			name: "json unmarshaler type with invalid value",
 			conf: Config{
-				Model: struct {
-					CreatedAt time.Time `rql:"filter"`
-				}{},
+				Model: new(struct {
+					Price testDecimal `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
 				"filter": {
-					"created_at": 12736186894
+					"price": { "$in": [1, true] }
 				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "mismatch bool type",
+			name: "ip address in subnet",
 			conf: Config{
-				Model: struct {
-					Admin bool `rql:"filter"`
-				}{},
+				Model: new(struct {
+					IP net.IP `rql:"filter"`
+				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"admin": "false"
+					"ip": { "$insubnet": "10.0.0.0/8", "$neq": "10.0.0.1" }
 				}
 			}`),
-			wantErr: true,
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(ip << ? AND ip <> ?)",
+				FilterArgs: []interface{}{"10.0.0.0/8", "10.0.0.1"},
+			},
 		},
 		{
-			name: "mismatch float type",
+			name: "ip address with invalid subnet",
 			conf: Config{
-				Model: struct {
-					Age float32 `rql:"filter"`
-				}{},
+				Model: new(struct {
+					IP net.IP `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
 				"filter": {
-					"age": "13"
+					"ip": { "$insubnet": "10.0.0.1" }
 				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "unrecognized fields",
+			name: "subnet on non-ip field",
 			conf: Config{
-				Model: struct {
-					Name string `rql:"filter"`
-				}{},
+				Model: new(struct {
+					Host string `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
 				"filter": {
-					"age": "a8m"
+					"host": { "$insubnet": "10.0.0.0/8" }
 				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "field is not sortable",
+			name: "text unmarshaler type with invalid text",
 			conf: Config{
-				Model: struct {
-					Name string `rql:"filter"`
-				}{},
+				Model: new(struct {
+					ID testUUID `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
-				"sort": ["name"]
+				"filter": {
+					"id": "not-a-uuid"
+				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "invalid operation",
+			name: "text unmarshaler type with non-string value",
 			conf: Config{
-				Model: struct {
-					Name string `rql:"filter"`
-				}{},
+				Model: new(struct {
+					ID testUUID `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
 				"filter": {
-					"name": {
-						"$gt": 10
-					}
+					"id": 10
 				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "unrecognized operation",
+			name: "text unmarshaler type with unsupported op",
 			conf: Config{
-				Model: struct {
-					Name string `rql:"filter"`
-				}{},
+				Model: new(struct {
+					ID testUUID `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
 				"filter": {
-					"name": {
-						"$regex": ".*"
-					}
+					"id": { "$gt": "6ba7b810-9dad-11d1-80b4-00c04fd430c8" }
 				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "limit and offset",
+			name: "size operator",
 			conf: Config{
-				Model: struct{}{},
+				Model: new(struct {
+					Tags   []string `rql:"filter"`
+					Scores [3]int   `rql:"filter"`
+				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
-				"limit": 10,
-				"offset": 4
+				"filter": {
+					"tags": { "$size": 3 },
+					"scores": { "$size": 0 }
+				}
 			}`),
 			wantOut: &Params{
-				Limit:  10,
-				Offset: 4,
+				Limit:      25,
+				FilterExp:  "cardinality(tags) = ? AND cardinality(scores) = ?",
+				FilterArgs: []interface{}{3, 0},
 			},
 		},
 		{
-			name: "invalid offset",
+			name: "size operator with negative value",
 			conf: Config{
-				Model: struct{}{},
+				Model: new(struct {
+					Tags []string `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
-				"limit": 10,
-				"offset": -14
+				"filter": {
+					"tags": { "$size": -1 }
+				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "invalid limit 1",
+			name: "equality on array field",
 			conf: Config{
-				Model: struct{}{},
+				Model: new(struct {
+					Tags []string `rql:"filter"`
+				}),
 			},
 			input: []byte(`{
-				"limit": -10
+				"filter": {
+					"tags": ["a"]
+				}
 			}`),
 			wantErr: true,
 		},
 		{
-			name: "invalid limit 2",
+			name: "bare scalar on array fields",
 			conf: Config{
-				Model:         struct{}{},
-				LimitMaxValue: 100,
+				Model: new(struct {
+					Tags   []string    `rql:"filter"`
+					Scores []int       `rql:"filter"`
+					Dates  []time.Time `rql:"filter"`
+				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
-				"limit": 200
+				"filter": {
+					"tags": "go",
+					"scores": 10,
+					"dates": "2018-01-14T06:05:48.839Z"
+				}
 			}`),
-			wantErr: true,
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "? = ANY(tags) AND ? = ANY(scores) AND ? = ANY(dates)",
+				FilterArgs: []interface{}{"go", 10, mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z")},
+			},
 		},
 		{
-			name: "support name struct opt",
+			name: "has operator on array field",
 			conf: Config{
-				Model: struct {
-					SomeName string `rql:"filter,name=someName,column=some_name"`
-				}{},
+				Model: new(struct {
+					Tags []string `rql:"filter"`
+				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"someName": {
-						"$eq": "someName"
-					}
+					"tags": { "$has": "go", "$size": 2 }
 				}
 			}`),
 			wantOut: &Params{
 				Limit:      25,
-				FilterExp:  "some_name = ?",
-				FilterArgs: []interface{}{"someName"},
+				FilterExp:  "(? = ANY(tags) AND cardinality(tags) = ?)",
+				FilterArgs: []interface{}{"go", 2},
 			},
 		},
 		{
-			name: "backwards compatibility to error with mismatching keys and no namefn",
+			name: "contains operator on array fields",
 			conf: Config{
-				Model: struct {
-					SomeName string `rql:"filter"`
-				}{},
+				Model: new(struct {
+					Tags   []string `rql:"filter"`
+					Scores []int    `rql:"filter"`
+				}),
+				DefaultLimit: 25,
 			},
 			input: []byte(`{
 				"filter": {
-					"someName": {
-						"$eq": "someName"
-					}
+					"tags": { "$contains": ["go", "sql"] },
+					"scores": { "$contains": [1] }
 				}
 			}`),
-			wantErr: true,
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "tags @> ? AND scores @> ?",
+				FilterArgs: []interface{}{[]interface{}{"go", "sql"}, []interface{}{1}},
+			},
 		},
 		{
-			name: "test nameFn works",
+			name: "contains operator with custom statement",
 			conf: Config{
-				Model: struct {
-					SomeName string `rql:"filter,name=someName,column=some_name"`
-				}{},
-				NameFn: Column,
+				Model: new(struct {
+					Tags []string `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+				GetDBStatement: func(o Op, f *FieldMeta) (string, string) {
+					if o == CONTAINS {
+						return "JSON_CONTAINS", "%[2]v(%[1]v, %[3]v)"
+					}
+					return "=", "%v %v %v"
+				},
 			},
 			input: []byte(`{
 				"filter": {
-					"someName": {
-						"$eq": "someName"
-					}
+					"tags": { "$contains": ["go"] }
 				}
 			}`),
 			wantOut: &Params{
 				Limit:      25,
-				FilterExp:  "some_name = ?",
-				FilterArgs: []interface{}{"someName"},
+				FilterExp:  "JSON_CONTAINS(tags, ?)",
+				FilterArgs: []interface{}{[]interface{}{"go"}},
 			},
 		},
 		{
-			name: "custom db symbols",
+			name: "contains operator with invalid element",
 			conf: Config{
-				Model: struct {
-					ID           string `rql:"filter"`
-					FullName     string `rql:"filter"`
-					HTTPUrl      string `rql:"filter"`
-					NestedStruct struct {
-						UUID string `rql:"filter"`
-					}
-				}{},
-				FieldSep: ".",
-				GetDBStatement: func(o Op, f *FieldMeta) (string, string) {
-					if o == EQ {
-						return "eq", "%v %v %v"
-					}
-					return opFormat[o], "%v %v %v"
+				Model: new(struct {
+					Scores []int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"scores": { "$contains": [1, "two"] }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "contains operator with scalar",
+			conf: Config{
+				Model: new(struct {
+					Tags []string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"tags": { "$contains": "go" }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "contains operator on non-array field",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"name": { "$contains": ["go"] }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "bare scalar with invalid type on array field",
+			conf: Config{
+				Model: new(struct {
+					Scores []int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"scores": "ten"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "bare scalar on array field with forced equality",
+			conf: Config{
+				Model: new(struct {
+					Tags []string `rql:"filter"`
+				}),
+				ArrayScalarOp: EQ,
+			},
+			input: []byte(`{
+				"filter": {
+					"tags": "go"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "bare scalar on array field with forced equality and custom ops",
+			conf: Config{
+				Model: new(struct {
+					Tags []string `rql:"filter"`
+				}),
+				DefaultLimit:  25,
+				ArrayScalarOp: EQ,
+				GetSupportedOps: func(f *FieldMeta) []Op {
+					return []Op{EQ}
 				},
-				GetDBDir: func(d Direction) string {
-					if d == ASC {
-						return "ASC"
-					}
-					return "DESC"
+				GetValidator: func(f *FieldMeta) Validator {
+					return validateString
 				},
 			},
 			input: []byte(`{
 				"filter": {
-					"id": "id",
-					"full_name": "full_name",
-					"http_url": "http_url",
-					"nested_struct.uuid": "uuid"
+					"tags": "go"
 				}
 			}`),
 			wantOut: &Params{
 				Limit:      25,
-				FilterExp:  "id eq ? AND full_name eq ? AND http_url eq ? AND nested_struct_uuid eq ?",
-				FilterArgs: []interface{}{"id", "full_name", "http_url", "uuid"},
-				Sort:       "",
+				FilterExp:  "tags = ?",
+				FilterArgs: []interface{}{"go"},
 			},
 		},
 		{
-			name: "alternate parameter symbol test",
+			name: "default string op",
 			conf: Config{
 				Model: new(struct {
-					Age     int    `rql:"filter"`
-					Name    string `rql:"filter"`
-					Address string `rql:"filter"`
-					Other   string
+					Name  string         `rql:"filter"`
+					Title sql.NullString `rql:"filter"`
+					Email string         `rql:"filter,op=eq"`
+					Age   int            `rql:"filter"`
 				}),
-				ParamSymbol:  "@",
-				DefaultLimit: 25,
+				DefaultStringOp: LIKE,
 			},
 			input: []byte(`{
-					"filter": {
-						"name": "foo",
-						"age": 12,
-						"$or": [
-							{ "address": "DC" },
-							{ "address": "Marvel" }
-						],
-						"$and": [
-							{ "age": { "$neq": 10} },
-							{ "age": { "$neq": 20} },
-							{ "$or": [{ "age": 11 }, {"age": 10}] }
-						]
-					}
-				}`),
+				"filter": {
+					"$and": [
+						{ "name": "a8m" },
+						{ "title": "dev" },
+						{ "email": "a8m@example.com" },
+						{ "age": 10 },
+						{ "name": { "$eq": "a8m" } }
+					]
+				}
+			}`),
 			wantOut: &Params{
-				Limit:       25,
-				FilterExp:   "name = @ AND age = @ AND (address = @ OR address = @) AND (age <> @ AND age <> @ AND (age = @ OR age = @))",
-				FilterArgs:  []interface{}{"foo", 12, "DC", "Marvel", 10, 20, 11, 10},
-				ParamSymbol: "@",
+				Limit:      25,
+				FilterExp:  "(name LIKE ? AND title = ? AND email = ? AND age = ? AND name = ?)",
+				FilterArgs: []interface{}{"a8m%", "dev", "a8m@example.com", 10, "a8m"},
 			},
 		},
 		{
-			name: "positional parameter symbol test",
+			name: "default string op in tag",
 			conf: Config{
 				Model: new(struct {
-					Age     int    `rql:"filter"`
-					Name    string `rql:"filter"`
-					Address string `rql:"filter"`
-					Other   string
+					Name string `rql:"filter,op=ilike"`
 				}),
-				ParamSymbol:      "$",
-				PositionalParams: true,
-				DefaultLimit:     25,
 			},
 			input: []byte(`{
 				"filter": {
-					"name": "foo",
-					"age": 12,
-					"$or": [
-						{ "address": "DC" },
-						{ "address": "Marvel" }
-					],
-					"$and": [
-						{ "age": { "$neq": 10} },
-						{ "age": { "$neq": 20} },
-						{ "$or": [{ "age": 11 }, {"age": 10}] }
-					]
+					"name": "a8m"
 				}
 			}`),
 			wantOut: &Params{
-				Limit:            25,
-				FilterExp:        "name = $1 AND age = $2 AND (address = $3 OR address = $4) AND (age <> $5 AND age <> $6 AND (age = $7 OR age = $8))",
-				FilterArgs:       []interface{}{"foo", 12, "DC", "Marvel", 10, 20, 11, 10},
-				ParamSymbol:      "$",
-				PositionalParams: true,
+				Limit:      25,
+				FilterExp:  "name ILIKE ?",
+				FilterArgs: []interface{}{"a8m%"},
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tt.conf.Log = t.Logf
-			p, err := NewParser(tt.conf)
-			if err != nil {
-				t.Fatalf("failed to build parser: %v", err)
-			}
-			out, err := p.Parse(tt.input)
-			if tt.wantErr != (err != nil) {
-				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
-			}
-			assertParams(t, out, tt.wantOut)
-		})
-	}
-}
-
-// AssertQueryEqual tests if two query input are equal.
-// TODO: improve this in the future.
-func assertParams(t *testing.T, got *Params, want *Params) {
-	if got == nil && want == nil {
-		return
-	}
-	if got.Limit != want.Limit {
-		t.Fatalf("limit: got: %v want %v", got.Limit, want.Limit)
-	}
-	if got.Offset != want.Offset {
-		t.Fatalf("offset: got: %v want %v", got.Limit, want.Limit)
-	}
-	if got.Sort != want.Sort {
-		t.Fatalf("sort: got: %q want %q", got.Sort, want.Sort)
-	}
-	if got.Select != want.Select {
-		t.Fatalf("select: got: %q want %q", got.Select, want.Select)
-	}
-	if !equalExp(got.FilterExp, want.FilterExp, got.ParamSymbol, got.PositionalParams) || !equalExp(want.FilterExp, got.FilterExp, want.ParamSymbol, want.PositionalParams) {
-		t.Fatalf("filter expr:\n\tgot: %q\n\twant %q", got.FilterExp, want.FilterExp)
-	}
-	err := deepEqualIgnoreOrder(got.FilterArgs, want.FilterArgs)
-	if err != nil {
-		t.Fatalf("filter expr:\n\tgot: %v\n\twant %v.\n%v", got.FilterArgs, want.FilterArgs, err.Error())
-	}
+		{
+			name: "default string op with invalid value",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter"`
+				}),
+				DefaultStringOp: LIKE,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": 10
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "size operator on non-array field",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"name": { "$size": 1 }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "search operator",
+			conf: Config{
+				Model: new(struct {
+					Title string         `rql:"filter,search"`
+					Body  sql.NullString `rql:"filter,search"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"title": { "$search": "fat cats" },
+					"body": { "$search": "rat" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "to_tsvector(title) @@ plainto_tsquery(?) AND to_tsvector(body) @@ plainto_tsquery(?)",
+				FilterArgs: []interface{}{"fat cats", "rat"},
+			},
+		},
+		{
+			name: "search operator with custom statement",
+			conf: Config{
+				Model: new(struct {
+					Title string `rql:"filter,search"`
+				}),
+				DefaultLimit: 25,
+				GetDBStatement: func(o Op, f *FieldMeta) (string, string) {
+					if o == SEARCH {
+						return "AGAINST", "MATCH(%[1]v) %[2]v (%[3]v)"
+					}
+					return "=", "%v %v %v"
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"title": { "$search": "cats" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "MATCH(title) AGAINST (?)",
+				FilterArgs: []interface{}{"cats"},
+			},
+		},
+		{
+			name: "search operator on non-search field",
+			conf: Config{
+				Model: new(struct {
+					Title string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"title": { "$search": "cats" }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "search operator with invalid value",
+			conf: Config{
+				Model: new(struct {
+					Title string `rql:"filter,search"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"title": { "$search": 1 }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "valid operations with ilike",
+			conf: Config{
+				Model: new(struct {
+					Age     int    `rql:"filter"`
+					Name    string `rql:"filter"`
+					Address string `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"age": { "$gt": 10 },
+					"name": { "$ilike": "%foo%" },
+					"$or": [
+						{ "address": { "$eq": "DC" } },
+						{ "address": { "$neq": "Marvel" } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "age > ? AND name ILIKE ? AND (address = ? OR address <> ?)",
+				FilterArgs: []interface{}{10, "%foo%", "DC", "Marvel"},
+			},
+		},
+		{
+			name: "ilike rewritten by custom db statement",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter"`
+				}),
+				GetDBStatement: func(o Op, f *FieldMeta) (string, string) {
+					if o == ILIKE {
+						return "LIKE", "LOWER(%v) %v LOWER(%v)"
+					}
+					return opFormat[o], "%v %v %v"
+				},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": { "$ilike": "%foo%" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "LOWER(name) LIKE LOWER(?)",
+				FilterArgs: []interface{}{"%foo%"},
+			},
+		},
+		{
+			name: "ilike on non-string field",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"age": { "$ilike": "%0" }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "ops whitelist",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter,ops=eq|neq"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "name": "foo" },
+						{ "name": { "$neq": "bar" } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(name = ? OR name <> ?)",
+				FilterArgs: []interface{}{"foo", "bar"},
+			},
+		},
+		{
+			name: "ops whitelist rejects other ops",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter,ops=eq|neq"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"name": { "$like": "%foo%" }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "ops whitelist rejects default equality",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter,ops=like"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "foo"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "neq default rendering",
+			conf: Config{
+				Model: new(struct {
+					Age *int `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [{ "age": { "$neq": 10 } }, { "age": { "$null": true } }]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(age <> ? OR age IS NULL)",
+				FilterArgs: []interface{}{10},
+			},
+		},
+		{
+			name: "neq custom rendering",
+			conf: Config{
+				Model: new(struct {
+					Age *int `rql:"filter"`
+				}),
+				NotEqualOp:   "!=",
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [{ "age": { "$neq": 10 } }, { "age": { "$null": false } }]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(age != ? OR age IS NOT NULL)",
+				FilterArgs: []interface{}{10},
+			},
+		},
+		{
+			name: "in and nin operators",
+			conf: Config{
+				Model: new(struct {
+					Age  uint   `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"age": { "$in": [1, 2] },
+					"name": { "$nin": ["foo", "bar"] }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "age IN (?, ?) AND name NOT IN (?, ?)",
+				FilterArgs: []interface{}{1, 2, "foo", "bar"},
+			},
+		},
+		{
+			name: "negative value inside in on uint field",
+			conf: Config{
+				Model: new(struct {
+					Age uint `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"age": { "$in": [1, -2] }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "negative comparison on uint field",
+			conf: Config{
+				Model: new(struct {
+					Age uint `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"age": { "$gt": -1 }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "negative comparison on uint field allowed by policy",
+			conf: Config{
+				Model: new(struct {
+					Age uint `rql:"filter"`
+				}),
+				AllowNegativeUintBounds: true,
+				DefaultLimit:            25,
+			},
+			input: []byte(`{
+				"filter": {
+					"age": { "$gt": -1 }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "age > ?",
+				FilterArgs: []interface{}{-1},
+			},
+		},
+		{
+			name: "negative equality on uint field with policy",
+			conf: Config{
+				Model: new(struct {
+					Age uint `rql:"filter"`
+				}),
+				AllowNegativeUintBounds: true,
+			},
+			input: []byte(`{
+				"filter": {
+					"age": { "$in": [-1] }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "not operator",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "foo",
+					"$not": { "age": { "$gt": 10 } },
+					"$or": [
+						{ "$not": { "$or": [{ "name": "bar" }, { "name": "baz" }] } },
+						{ "$not": { "$not": { "age": 1 } } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "name = ? AND NOT (age > ?) AND (NOT ((name = ? OR name = ?)) OR NOT (NOT (age = ?)))",
+				FilterArgs: []interface{}{"foo", 10, "bar", "baz", 1},
+			},
+		},
+		{
+			name: "negated filter",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [{ "name": "foo" }, { "age": { "$gt": 10 } }]
+				},
+				"negate": true
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "NOT ((name = ? OR age > ?))",
+				FilterArgs: []interface{}{"foo", 10},
+			},
+		},
+		{
+			name: "negated filter with one field",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"negate": true
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "NOT (name = ?)",
+				FilterArgs: []interface{}{"foo"},
+			},
+		},
+		{
+			name: "negated empty filter",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter"`
+				}),
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"negate": true
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+			},
+		},
+		{
+			name: "not operator with array",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$not": [{ "age": 10 }]
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "not operator with empty object",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$not": {}
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "custom operation prefix",
+			conf: Config{
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter"`
+					Work      struct {
+						Address string `rql:"filter"`
+						Salary  int    `rql:"filter"`
+					}
+				}),
+				OpPrefix:     "@",
+				FieldSep:     "#",
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": { "@gt": "2018-01-14T06:05:48.839Z" },
+					"work#address": { "@like": "%DC%" },
+					"@or": [
+						{
+							"work#salary": 100
+						},
+						{
+							"work#salary": { "@gte": 200, "@lte": 300 }
+						}
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "created_at > ? AND work_address LIKE ? AND (work_salary = ? OR (work_salary >= ? AND work_salary <= ?))",
+				FilterArgs: []interface{}{mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"), "%DC%", 100, 200, 300},
+			},
+		},
+		{
+			name: "sort",
+			conf: Config{
+				Model: struct {
+					Age     int    `rql:"filter,sort"`
+					Name    string `rql:"filter,sort"`
+					Address struct {
+						Name string `rql:"filter,sort"`
+						ZIP  *struct {
+							Code int `rql:"filter,sort"`
+						}
+					}
+				}{},
+				FieldSep:     ".",
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"address.zip.code": 100
+				},
+				"sort": ["address.name", "-address.zip.code", "+age"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "address_zip_code = ?",
+				FilterArgs: []interface{}{100},
+				Sort:       "address_name, address_zip_code desc, age asc",
+			},
+		},
+		{
+			name: "sort with default field separator",
+			conf: Config{
+				Model: struct {
+					Age     int    `rql:"filter,sort"`
+					Name    string `rql:"filter,sort"`
+					Address struct {
+						Name string `rql:"filter,sort"`
+						ZIP  *struct {
+							Code int `rql:"filter,sort"`
+						}
+					}
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"address_zip_code": 100
+				},
+				"sort": ["address_name", "-address_zip_code", "+age"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "address_zip_code = ?",
+				FilterArgs: []interface{}{100},
+				Sort:       "address_name, address_zip_code desc, age asc",
+			},
+		},
+		{
+			name: "sort with default sort field configured, and no sort in query",
+			conf: Config{
+				Model: struct {
+					Age     int    `rql:"filter,sort"`
+					Name    string `rql:"filter,sort"`
+					Address struct {
+						Name string `rql:"filter,sort"`
+						ZIP  *struct {
+							Code int `rql:"filter,sort"`
+						}
+					}
+				}{},
+				DefaultLimit: 25,
+				DefaultSort:  []string{"-name"},
+			},
+			input: []byte(`{
+				"filter": {
+					"address_zip_code": 100
+				},
+				"sort": []
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "address_zip_code = ?",
+				FilterArgs: []interface{}{100},
+				Sort:       "name desc",
+			},
+		},
+		{
+			name: "nested default sort field with custom field separator",
+			conf: Config{
+				Model: struct {
+					Age     int `rql:"filter,sort"`
+					Address struct {
+						Name string `rql:"filter,sort"`
+					}
+				}{},
+				FieldSep:     ".",
+				DefaultLimit: 25,
+				DefaultSort:  []string{"-address.name", "age"},
+			},
+			input: []byte(`{
+				"filter": {
+					"address.name": "TLV"
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "address_name = ?",
+				FilterArgs: []interface{}{"TLV"},
+				Sort:       "address_name desc, age",
+			},
+		},
+		{
+			name: "sort with default sort field configured, and sort specified in query",
+			conf: Config{
+				Model: struct {
+					Age     int    `rql:"filter,sort"`
+					Name    string `rql:"filter,sort"`
+					Address struct {
+						Name string `rql:"filter,sort"`
+						ZIP  *struct {
+							Code int `rql:"filter,sort"`
+						}
+					}
+				}{},
+				DefaultLimit: 25,
+				DefaultSort:  []string{"-name"},
+			},
+			input: []byte(`{
+				"filter": {
+					"address_zip_code": 100
+				},
+				"sort": ["-age"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "address_zip_code = ?",
+				FilterArgs: []interface{}{100},
+				Sort:       "age desc",
+			},
+		},
+		{
+			name: "sort with collation",
+			conf: Config{
+				Model: struct {
+					Name  string         `rql:"filter,sort,collate=en_US"`
+					Title sql.NullString `rql:"sort,collate=de_DE,nulls=last"`
+					Age   int            `rql:"filter,sort"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["-name", "title", "age"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  `name COLLATE "en_US" desc, title COLLATE "de_DE" NULLS LAST, age`,
+			},
+		},
+		{
+			name: "sort with custom collation",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter,sort,collate=utf8mb4_unicode_ci"`
+				}{},
+				DefaultLimit: 25,
+				GetDBCollate: func(collation string) string {
+					return "COLLATE " + collation
+				},
+			},
+			input: []byte(`{
+				"sort": ["+name"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "name COLLATE utf8mb4_unicode_ci asc",
+			},
+		},
+		{
+			name: "sort with tiebreaker",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Name string `rql:"filter,sort"`
+				}{},
+				DefaultLimit:   25,
+				SortTiebreaker: []string{"-id"},
+			},
+			input: []byte(`{
+				"sort": ["name"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "name, id desc",
+			},
+		},
+		{
+			name: "sort with tiebreaker that is already sorted",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Name string `rql:"filter,sort"`
+				}{},
+				DefaultLimit:   25,
+				SortTiebreaker: []string{"-id"},
+			},
+			input: []byte(`{
+				"sort": ["+id", "name"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "id asc, name",
+			},
+		},
+		{
+			name: "sort with tiebreaker and without sort",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Name string `rql:"filter,sort"`
+				}{},
+				DefaultLimit:   25,
+				SortTiebreaker: []string{"id"},
+			},
+			input: []byte(`{}`),
+			wantOut: &Params{
+				Limit: 25,
+			},
+		},
+		{
+			name: "sort with default sort, tiebreaker and nulls placement for nullable fields",
+			conf: Config{
+				Model: struct {
+					ID    int     `rql:"filter,sort"`
+					Name  *string `rql:"filter,sort"`
+					Score int     `rql:"filter,sort"`
+				}{},
+				DefaultLimit:   25,
+				DefaultSort:    []string{"-name", "score"},
+				SortTiebreaker: []string{"id"},
+				SortNulls:      NullsLast,
+			},
+			input: []byte(`{}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "name desc NULLS LAST, score, id",
+			},
+		},
+		{
+			name: "sort replaces default sort",
+			conf: Config{
+				Model: struct {
+					ID    int    `rql:"filter,sort"`
+					Name  string `rql:"filter,sort"`
+					Score int    `rql:"filter,sort"`
+				}{},
+				DefaultLimit: 25,
+				DefaultSort:  []string{"name", "-score"},
+			},
+			input: []byte(`{
+				"sort": ["-name"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "name desc",
+			},
+		},
+		{
+			name: "sort appends default sort",
+			conf: Config{
+				Model: struct {
+					ID    int    `rql:"filter,sort"`
+					Name  string `rql:"filter,sort"`
+					Score int    `rql:"filter,sort"`
+				}{},
+				DefaultLimit:     25,
+				DefaultSort:      []string{"name", "-score"},
+				DefaultSortMerge: SortMergeAppend,
+				SortTiebreaker:   []string{"id"},
+			},
+			input: []byte(`{
+				"sort": ["-name"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "name desc, score desc, id",
+			},
+		},
+		{
+			name: "sort appends default sort without request sort",
+			conf: Config{
+				Model: struct {
+					ID    int    `rql:"filter,sort"`
+					Name  string `rql:"filter,sort"`
+					Score int    `rql:"filter,sort"`
+				}{},
+				DefaultLimit:     25,
+				DefaultSort:      []string{"name", "-score"},
+				DefaultSortMerge: SortMergeAppend,
+			},
+			input: []byte(`{}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "name, score desc",
+			},
+		},
+		{
+			name: "sort with nulls placement for nullable fields",
+			conf: Config{
+				Model: struct {
+					ID        int            `rql:"filter,sort"`
+					DeletedAt *time.Time     `rql:"filter,sort,nulls=first"`
+					Email     sql.NullString `rql:"filter,sort"`
+					Phone     sql.NullString `rql:"filter,sort"`
+				}{},
+				DefaultLimit:   25,
+				SortTiebreaker: []string{"id"},
+				SortNulls:      NullsLast,
+			},
+			input: []byte(`{
+				"sort": ["-deleted_at", "email", "-phone nullsfirst"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "deleted_at desc NULLS FIRST, email NULLS LAST, phone desc NULLS FIRST, id",
+			},
+		},
+		{
+			name: "sort by joined field adds the join",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter,sort"`
+					City string `rql:"filter,sort,column=addresses.city,join=JOIN addresses ON addresses.user_id = users.id"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "foo"
+				},
+				"sort": ["-addresses.city"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "name = ?",
+				FilterArgs: []interface{}{"foo"},
+				Sort:       "addresses.city desc",
+				Joins:      []string{"JOIN addresses ON addresses.user_id = users.id"},
+			},
+		},
+		{
+			name: "joins of filter and sort are deduplicated",
+			conf: Config{
+				Model: struct {
+					Name    string `rql:"filter,sort"`
+					City    string `rql:"filter,sort,column=addresses.city,join=JOIN addresses ON addresses.user_id = users.id"`
+					Street  string `rql:"filter,sort,column=addresses.street,join=JOIN addresses ON addresses.user_id = users.id"`
+					Company string `rql:"filter,sort,column=companies.name,join=JOIN companies ON companies.id = users.company_id"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"addresses.city": "TLV"
+				},
+				"sort": ["addresses.street", "companies.name", "addresses.city"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "addresses.city = ?",
+				FilterArgs: []interface{}{"TLV"},
+				Sort:       "addresses.street, companies.name, addresses.city",
+				Joins: []string{
+					"JOIN addresses ON addresses.user_id = users.id",
+					"JOIN companies ON companies.id = users.company_id",
+				},
+			},
+		},
+		{
+			name: "trim keys",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter,sort"`
+				}{},
+				TrimKeys:     true,
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					" name": "foo",
+					"$or ": [{ "age ": 10 }, { "age": 11 }]
+				},
+				"select": [" name ", "age"],
+				"sort": [" -age"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "name = ? AND (age = ? OR age = ?)",
+				FilterArgs: []interface{}{"foo", 10, 11},
+				Select:     "name, age",
+				Sort:       "age desc",
+			},
+		},
+		{
+			name: "padded keys without trimming",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter,sort"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					" name": "foo"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "table prefix",
+			conf: Config{
+				Model: struct {
+					Age     int    `rql:"filter,sort"`
+					Name    string `rql:"filter,sort"`
+					Company string `rql:"filter,sort,table=companies"`
+					City    string `rql:"filter,column=addresses.city"`
+					Address struct {
+						Name string `rql:"filter,sort"`
+					}
+				}{},
+				TablePrefix:  "users",
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "foo",
+					"address_name": "DC",
+					"company": "GitHub",
+					"addresses.city": "TLV"
+				},
+				"select": ["name", "address_name", "company"],
+				"sort": ["-age", "address_name", "company"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "users.name = ? AND users.address_name = ? AND companies.company = ? AND addresses.city = ?",
+				FilterArgs: []interface{}{"foo", "DC", "GitHub", "TLV"},
+				Select:     "users.name, users.address_name, companies.company",
+				Sort:       "users.age desc, users.address_name, companies.company",
+			},
+		},
+		{
+			name: "schema and table prefix",
+			conf: Config{
+				Model: struct {
+					Name    string `rql:"filter,sort"`
+					Company string `rql:"filter,sort,table=companies"`
+					City    string `rql:"filter,column=addresses.city"`
+				}{},
+				Schema:       "analytics",
+				TablePrefix:  "events",
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "foo",
+					"company": "GitHub",
+					"addresses.city": "TLV"
+				},
+				"select": ["name", "company"],
+				"sort": ["-name"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "analytics.events.name = ? AND analytics.companies.company = ? AND addresses.city = ?",
+				FilterArgs: []interface{}{"foo", "GitHub", "TLV"},
+				Select:     "analytics.events.name, analytics.companies.company",
+				Sort:       "analytics.events.name desc",
+			},
+		},
+		{
+			name: "schema and table prefix with quoting",
+			conf: Config{
+				Model: struct {
+					Name    string `rql:"filter,sort"`
+					Company string `rql:"filter,sort,table=companies"`
+					City    string `rql:"filter,column=addresses.city"`
+				}{},
+				Schema:       "analytics",
+				TablePrefix:  "events",
+				QuoteIdent:   DoubleQuote,
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "foo",
+					"company": "GitHub",
+					"addresses.city": "TLV"
+				},
+				"select": ["name", "company"],
+				"sort": ["-name"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  `"analytics"."events"."name" = ? AND "analytics"."companies"."company" = ? AND "addresses"."city" = ?`,
+				FilterArgs: []interface{}{"foo", "GitHub", "TLV"},
+				Select:     `"analytics"."events"."name", "analytics"."companies"."company"`,
+				Sort:       `"analytics"."events"."name" desc`,
+			},
+		},
+		{
+			name: "quoting without table prefix",
+			conf: Config{
+				Model: struct {
+					Name  string `rql:"filter,sort"`
+					Order int    `rql:"filter,sort"`
+				}{},
+				QuoteIdent: func(s string) string {
+					return "`" + s + "`"
+				},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"order": { "$gt": 1 }
+				},
+				"select": ["name", "order"],
+				"sort": ["order"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "`order` > ?",
+				FilterArgs: []interface{}{1},
+				Select:     "`name`, `order`",
+				Sort:       "`order`",
+			},
+		},
+		{
+			name: "cursor",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["id"],
+				"after": { "id": 1000 }
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				Sort:       "id",
+				CursorExp:  "id > ?",
+				CursorArgs: []interface{}{1000},
+			},
+		},
+		{
+			name: "escaped string ops",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "name": { "$startswith": "50%_off" } },
+						{ "name": { "$endswith": "a\\b" } },
+						{ "name": { "$includes": "a8m" } },
+						{ "name": { "$contains": "a_" } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  `(name LIKE ? ESCAPE '\' AND name LIKE ? ESCAPE '\' AND name LIKE ? ESCAPE '\' AND name LIKE ? ESCAPE '\')`,
+				FilterArgs: []interface{}{`50\%\_off%`, `%a\\b`, "%a8m%", `%a\_%`},
+			},
+		},
+		{
+			name: "escaped string ops validation",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": { "name": { "$includes": 10 } }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "escape like",
+			conf: Config{
+				Model: struct {
+					Name  string `rql:"filter"`
+					Email string `rql:"filter"`
+				}{},
+				EscapeLike:      true,
+				DefaultStringOp: LIKE,
+			},
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "name": { "$like": "50\\%%" } },
+						{ "email": "a_8m" }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  `(name LIKE ? ESCAPE '\' AND email LIKE ? ESCAPE '\')`,
+				FilterArgs: []interface{}{`50\%%`, `a\_8m%`},
+			},
+		},
+		{
+			name: "field sort direction",
+			conf: Config{
+				Model: struct {
+					ID        int       `rql:"filter,sort"`
+					CreatedAt time.Time `rql:"sort,dir=desc"`
+					Name      string    `rql:"sort,dir=asc"`
+				}{},
+			},
+			input: []byte(`{
+				"sort": ["created_at", "name", "id"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "created_at desc, name asc, id",
+			},
+		},
+		{
+			name: "field sort direction is overridden by the prefix",
+			conf: Config{
+				Model: struct {
+					ID        int       `rql:"filter,sort"`
+					CreatedAt time.Time `rql:"sort,dir=desc"`
+				}{},
+			},
+			input: []byte(`{
+				"sort": ["+created_at", "-id"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "created_at asc, id desc",
+			},
+		},
+		{
+			name: "field sort direction in cursor",
+			conf: Config{
+				Model: struct {
+					ID int `rql:"filter,sort,dir=desc"`
+				}{},
+			},
+			input: []byte(`{
+				"sort": ["id"],
+				"after": { "id": 1000 }
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				Sort:       "id desc",
+				CursorExp:  "id < ?",
+				CursorArgs: []interface{}{1000},
+			},
+		},
+		{
+			name: "repeated sort field",
+			conf: Config{
+				Model: struct {
+					Age int `rql:"sort"`
+				}{},
+			},
+			input: []byte(`{
+				"sort": ["age", "age"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "repeated sort field with prefix",
+			conf: Config{
+				Model: struct {
+					Age int `rql:"sort"`
+				}{},
+			},
+			input: []byte(`{
+				"sort": ["age", "-age"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "max sort fields",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"sort"`
+					Age  int    `rql:"sort"`
+					Name string `rql:"sort"`
+				}{},
+				MaxSortFields:  2,
+				SortTiebreaker: []string{"id"},
+			},
+			input: []byte(`{
+				"sort": ["-age", "name"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "age desc, name, id",
+			},
+		},
+		{
+			name: "max sort fields exceeded",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"sort"`
+					Age  int    `rql:"sort"`
+					Name string `rql:"sort"`
+				}{},
+				MaxSortFields: 2,
+			},
+			input: []byte(`{
+				"sort": ["-age", "name", "id"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "dedup sort",
+			conf: Config{
+				Model: struct {
+					ID  int `rql:"sort"`
+					Age int `rql:"sort"`
+				}{},
+				DedupSort: true,
+			},
+			input: []byte(`{
+				"sort": ["-age", "id", "+age", "id"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "age desc, id",
+			},
+		},
+		{
+			name: "custom logical keywords",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				AndKeyword: "&&",
+				OrKeyword:  "||",
+			},
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "name": "a8m" },
+						{ "$or": [{ "age": 1 }, { "$and": [{ "age": { "$gt": 10 } }, { "age": { "$lt": 20 } }] }] }
+					]
+				},
+				"sort": ["-age", "id"],
+				"after": { "id": 1000, "age": 22 }
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(name = ? && (age = ? || (age > ? && age < ?)))",
+				FilterArgs: []interface{}{"a8m", 1, 10, 20},
+				Sort:       "age desc, id",
+				CursorExp:  "(age < ? || (age = ? && id > ?))",
+				CursorArgs: []interface{}{22, 22, 1000},
+			},
+		},
+		{
+			name: "cursor with mixed directions",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["-age", "+id"],
+				"after": { "id": 1000, "age": 22 }
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				Sort:       "age desc, id asc",
+				CursorExp:  "(age < ? OR (age = ? AND id > ?))",
+				CursorArgs: []interface{}{22, 22, 1000},
+			},
+		},
+		{
+			name: "cursor with filter and positional params",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit:     25,
+				ParamSymbol:      "$",
+				PositionalParams: true,
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"sort": ["-id"],
+				"after": { "id": 1000 }
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "name = $1",
+				FilterArgs: []interface{}{"foo"},
+				Sort:       "id desc",
+				CursorExp:  "id < $2",
+				CursorArgs: []interface{}{1000},
+			},
+		},
+		{
+			name: "cursor with default sort and tiebreaker",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit:   25,
+				DefaultSort:    []string{"-age"},
+				SortTiebreaker: []string{"id"},
+			},
+			input: []byte(`{
+				"after": { "age": 22, "id": 1000 }
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				Sort:       "age desc, id",
+				CursorExp:  "(age < ? OR (age = ? AND id > ?))",
+				CursorArgs: []interface{}{22, 22, 1000},
+			},
+		},
+		{
+			name: "cursor that does not match the sort",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["-age", "id"],
+				"after": { "id": 1000 }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "cursor with a field that is not sorted",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["id"],
+				"after": { "age": 22 }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "cursor with non-sortable field",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["id"],
+				"after": { "id": 1000, "name": "foo" }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "cursor with invalid type",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["id"],
+				"after": { "id": "foo" }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "cursor without sort",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"after": { "id": 1000 }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "select one",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter,sort"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"select": ["name"]
+			}`),
+			wantOut: &Params{
+				Limit:  25,
+				Select: "name",
+			},
+		},
+		{
+			name: "select many",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter,sort"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"select": ["name", "age"]
+			}`),
+			wantOut: &Params{
+				Limit:  25,
+				Select: "name, age",
+			},
+		},
+		{
+			name: "select column",
+			conf: Config{
+				Model: struct {
+					Age      int    `rql:"filter,sort"`
+					Name     string `rql:"filter,sort,name=name,column=full_name"`
+					Nickname string `rql:"filter,name=nick"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"select": ["name", "nick", "age"]
+			}`),
+			wantOut: &Params{
+				Limit:  25,
+				Select: "full_name, nickname, age",
+			},
+		},
+		{
+			name: "column expression",
+			conf: Config{
+				Model: struct {
+					Age      int    `rql:"filter,sort"`
+					FullName string `rql:"filter,sort,select"`
+				}{},
+				ColumnExpr:   map[string]string{"full_name": "(first_name || ' ' || last_name)"},
+				TablePrefix:  "users",
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"full_name": { "$like": "a8m%" },
+					"age": 20
+				},
+				"select": ["full_name"],
+				"sort": ["-full_name"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "users.age = ? AND (first_name || ' ' || last_name) LIKE ?",
+				FilterArgs: []interface{}{20, "a8m%"},
+				Select:     "(first_name || ' ' || last_name) AS full_name",
+				Sort:       "(first_name || ' ' || last_name) desc",
+			},
+		},
+		{
+			name: "select unrecognized field",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter,sort"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"select": ["name", "password"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "select selectable fields",
+			conf: Config{
+				Model: struct {
+					ID    int    `rql:"filter,sort,selectable"`
+					Email string `rql:"filter,selectable"`
+					Token string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": { "token": "abc" },
+				"select": ["id", "email"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "token = ?",
+				FilterArgs: []interface{}{"abc"},
+				Select:     "id, email",
+			},
+		},
+		{
+			name: "select filterable but not selectable field",
+			conf: Config{
+				Model: struct {
+					ID    int    `rql:"filter,sort,selectable"`
+					Token string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"select": ["token"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "select non-selectable field",
+			conf: Config{
+				Model: struct {
+					ID       int    `rql:"filter,sort,select"`
+					Password string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"select": ["id", "password"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "group by",
+			conf: Config{
+				Model: struct {
+					Status  string `rql:"filter,group"`
+					Region  string `rql:"filter,group,name=region,column=region_code"`
+					Address struct {
+						City string `rql:"group"`
+					}
+				}{},
+				DefaultLimit: 25,
+				FieldSep:     ".",
+			},
+			input: []byte(`{
+				"group": ["status", "region", "address.city"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Group: "status, region_code, address_city",
+			},
+		},
+		{
+			name: "group by with name function",
+			conf: Config{
+				Model: struct {
+					CreatedBy string `rql:"filter,group"`
+				}{},
+				DefaultLimit: 25,
+				NameFn: func(s string) string {
+					return strings.ToLower(s)
+				},
+			},
+			input: []byte(`{
+				"group": ["createdby"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Group: "created_by",
+			},
+		},
+		{
+			name: "group by non-groupable field",
+			conf: Config{
+				Model: struct {
+					Status string `rql:"filter,group"`
+					Region string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"group": ["status", "region"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "group by unknown field",
+			conf: Config{
+				Model: struct {
+					Status string `rql:"filter,group"`
+				}{},
+			},
+			input: []byte(`{
+				"group": ["region"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "select wildcard expansion",
+			conf: Config{
+				Model: struct {
+					ID       int    `rql:"filter,sort,select"`
+					Name     string `rql:"filter,sort,select,column=full_name"`
+					Password string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+				ExpandSelect: true,
+			},
+			input: []byte(`{
+				"select": ["*"]
+			}`),
+			wantOut: &Params{
+				Limit:  25,
+				Select: "id, full_name",
+			},
+		},
+		{
+			name: "select empty expansion",
+			conf: Config{
+				Model: struct {
+					ID       int    `rql:"filter,sort,select"`
+					Name     string `rql:"filter,sort,select"`
+					Password string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+				TablePrefix:  "users",
+				ExpandSelect: true,
+			},
+			input: []byte(`{}`),
+			wantOut: &Params{
+				Limit:  25,
+				Select: "users.id, users.name",
+			},
+		},
+		{
+			name: "select wildcard with other fields",
+			conf: Config{
+				Model: struct {
+					ID   int    `rql:"filter,sort,select"`
+					Name string `rql:"filter,sort,select"`
+				}{},
+				ExpandSelect: true,
+			},
+			input: []byte(`{
+				"select": ["*", "name"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "select wildcard without expansion",
+			conf: Config{
+				Model: struct {
+					ID int `rql:"filter,sort,select"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"select": ["*"]
+			}`),
+			wantOut: &Params{
+				Limit:  25,
+				Select: "*",
+			},
+		},
+		{
+			name: "denied select wildcard",
+			conf: Config{
+				Model: struct {
+					ID int `rql:"filter,sort,select"`
+				}{},
+				ExpandSelect:       true,
+				DenySelectWildcard: true,
+			},
+			input: []byte(`{
+				"select": ["*"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "select distinct",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter,sort"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"select": ["name"],
+				"distinct": true
+			}`),
+			wantOut: &Params{
+				Limit:    25,
+				Select:   "name",
+				Distinct: true,
+			},
+		},
+		{
+			name: "distinct without select",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter,sort"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"distinct": true
+			}`),
+			wantOut: &Params{
+				Limit:    25,
+				Distinct: true,
+			},
+		},
+		{
+			name: "distinct with invalid type",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter,sort"`
+				}{},
+			},
+			input: []byte(`{
+				"distinct": "yes"
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "custom column name",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter,column=full_name,sort"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"full_name": "a8m"
+				},
+				"sort": ["full_name"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "full_name = ?",
+				FilterArgs: []interface{}{"a8m"},
+				Sort:       "full_name",
+			},
+		},
+		{
+			name: "naming columns",
+			conf: Config{
+				Model: struct {
+					ID           string `rql:"filter"`
+					FullName     string `rql:"filter"`
+					HTTPUrl      string `rql:"filter"`
+					NestedStruct struct {
+						UUID string `rql:"filter"`
+					}
+				}{},
+				FieldSep: ".",
+			},
+			input: []byte(`{
+				"filter": {
+					"id": "id",
+					"full_name": "full_name",
+					"http_url": "http_url",
+					"nested_struct.uuid": "uuid"
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "id = ? AND full_name = ? AND http_url = ? AND nested_struct_uuid = ?",
+				FilterArgs: []interface{}{"id", "full_name", "http_url", "uuid"},
+			},
+		},
+		{
+			name: "sort with null placement",
+			conf: Config{
+				Model: struct {
+					Age       int        `rql:"filter,sort,nulls=first"`
+					Score     int        `rql:"filter,sort,nulls=first"`
+					Name      string     `rql:"filter,sort"`
+					CreatedAt *time.Time `rql:"filter,sort"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["-created_at nullslast", "age", "-score nullslast", "name nullsfirst"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "created_at desc NULLS LAST, age NULLS FIRST, score desc NULLS LAST, name NULLS FIRST",
+			},
+		},
+		{
+			name: "sort with custom null placement",
+			conf: Config{
+				Model: struct {
+					CreatedAt *time.Time `rql:"filter,sort"`
+				}{},
+				GetDBNulls: func(n Nulls) string {
+					return ""
+				},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"sort": ["-created_at nullslast"]
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+				Sort:  "created_at desc",
+			},
+		},
+		{
+			name: "sort with unknown null placement",
+			conf: Config{
+				Model: struct {
+					CreatedAt *time.Time `rql:"filter,sort"`
+				}{},
+			},
+			input: []byte(`{
+				"sort": ["-created_at nullsmiddle"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "time unix layout",
+			conf: Config{
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter,layout=UnixDate"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": { "$gt": "Thu May 23 09:30:06 IDT 2000" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "created_at > ?",
+				FilterArgs: []interface{}{mustParseTime(time.UnixDate, "Thu May 23 09:30:06 IDT 2000")},
+			},
+		},
+		{
+			name: "time custom layout",
+			conf: Config{
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter,layout=2006-01-02 15:04"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": { "$gt": "2006-01-02 15:04" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "created_at > ?",
+				FilterArgs: []interface{}{mustParseTime("2006-01-02 15:04", "2006-01-02 15:04")},
+			},
+		},
+		{
+			name: "time layout from registry",
+			conf: Config{
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter,layout=@shortdate"`
+					UpdatedAt time.Time `rql:"filter,layout=@shortdate"`
+				}),
+				Layouts: map[string]string{"shortdate": "2006-01-02"},
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": { "$gt": "2018-01-14" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "created_at > ?",
+				FilterArgs: []interface{}{mustParseTime("2006-01-02", "2018-01-14")},
+			},
+		},
+		{
+			name: "time multiple layouts",
+			conf: Config{
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter,layout=RFC3339|2006-01-02"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "created_at": { "$gt": "2018-01-14T06:05:48.839Z" } },
+						{ "created_at": { "$lt": "2018-01-14" } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:     25,
+				FilterExp: "(created_at > ? OR created_at < ?)",
+				FilterArgs: []interface{}{
+					mustParseTime(time.RFC3339, "2018-01-14T06:05:48.839Z"),
+					mustParseTime("2006-01-02", "2018-01-14"),
+				},
+			},
+		},
+		{
+			name: "like cast",
+			conf: Config{
+				Model: new(struct {
+					ID   int    `rql:"filter,likecast"`
+					Code string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"id": { "$like": "12%" },
+					"code": { "$ilike": "a%" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "CAST(id AS TEXT) LIKE ? AND code ILIKE ?",
+				FilterArgs: []interface{}{"12%", "a%"},
+			},
+		},
+		{
+			name: "like cast keeps the field ops",
+			conf: Config{
+				Model: new(struct {
+					ID int `rql:"filter,likecast"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "id": { "$gt": 10 } },
+						{ "id": { "$ilike": "%7" } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(id > ? OR CAST(id AS TEXT) ILIKE ?)",
+				FilterArgs: []interface{}{10, "%7"},
+			},
+		},
+		{
+			name: "like cast invalid pattern",
+			conf: Config{
+				Model: new(struct {
+					ID int `rql:"filter,likecast"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"id": { "$like": 12 }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "like without cast",
+			conf: Config{
+				Model: new(struct {
+					ID int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"id": { "$like": "12%" }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "op columns",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter,column=name,eqcolumn=name_lower,ilikecolumn=name_lower"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "name": "a8m" },
+						{ "name": { "$gt": "a", "$lt": "b" } },
+						{ "name": { "$ilike": "a%" } },
+						{ "name": { "$in": ["a8m", "noam"] } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(name_lower = ? OR (name > ? AND name < ?) OR name_lower ILIKE ? OR name IN (?, ?))",
+				FilterArgs: []interface{}{"a8m", "a", "b", "a%", "a8m", "noam"},
+			},
+		},
+		{
+			name: "top-level or",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": 1 },
+						{ "name": "a8m" }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(age = ? OR name = ?)",
+				FilterArgs: []interface{}{1, "a8m"},
+			},
+		},
+		{
+			name: "top-level and as the sole key",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "$or": [{ "age": 1 }, { "age": 2 }] },
+						{ "name": "a8m" }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "((age = ? OR age = ?) AND name = ?)",
+				FilterArgs: []interface{}{1, 2, "a8m"},
+			},
+		},
+		{
+			name: "empty groups are ignored",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+				AllowEmptyGroups: true,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{},
+						{ "$and": [] },
+						{ "$not": { "$or": [] } },
+						{ "age": 1 }
+					],
+					"$and": [{ "name": {} }]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "age = ?",
+				FilterArgs: []interface{}{1},
+			},
+		},
+		{
+			name: "negated empty group",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+				AllowEmptyGroups: true,
+			},
+			input: []byte(`{
+				"filter": { "$or": [] },
+				"negate": true
+			}`),
+			wantOut: &Params{
+				Limit: 25,
+			},
+		},
+		{
+			name: "empty or is rejected",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": { "$or": [] }
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "nested empty and is rejected",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": 1 },
+						{ "$and": [] }
+					]
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "empty and is dropped",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+				AllowEmptyGroups: true,
+			},
+			input: []byte(`{
+				"filter": {
+					"$and": [],
+					"age": 1
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "age = ?",
+				FilterArgs: []interface{}{1},
+			},
+		},
+		{
+			name: "or with a single child",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [{ "age": { "$gt": 1, "$lt": 10 } }],
+					"$and": [{ "age": { "$neq": 5 } }]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(age > ? AND age < ?) AND age <> ?",
+				FilterArgs: []interface{}{1, 10, 5},
+			},
+		},
+		{
+			name: "unlimited",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+				AllowUnlimited: true,
+			},
+			input: []byte(`{
+				"offset": 10
+			}`),
+			wantOut: &Params{
+				Limit:  0,
+				Offset: 10,
+			},
+		},
+		{
+			name: "unlimited with a limit above the max value",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+				AllowUnlimited: true,
+			},
+			input: []byte(`{
+				"limit": 101
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "unlimited with a page",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter"`
+				}),
+				AllowUnlimited: true,
+			},
+			input: []byte(`{
+				"page": 2
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "value bounds",
+			conf: Config{
+				Model: new(struct {
+					PageSize  int       `rql:"filter,min=0,max=1000"`
+					CreatedAt time.Time `rql:"filter,min=2000-01-01T00:00:00Z"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "page_size": { "$in": [0, 1000] } },
+						{ "page_size": { "$between": [10, 20] } },
+						{ "created_at": { "$gt": "2018-01-01T00:00:00Z" } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:     25,
+				FilterExp: "(page_size IN (?, ?) OR page_size BETWEEN ? AND ? OR created_at > ?)",
+				FilterArgs: []interface{}{
+					0,
+					1000,
+					10,
+					20,
+					mustParseTime(time.RFC3339, "2018-01-01T00:00:00Z"),
+				},
+			},
+		},
+		{
+			name: "value above max",
+			conf: Config{
+				Model: new(struct {
+					PageSize int `rql:"filter,min=0,max=1000"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"page_size": { "$gt": 1001 }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "list value below min",
+			conf: Config{
+				Model: new(struct {
+					PageSize int `rql:"filter,min=0,max=1000"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"page_size": { "$in": [10, -1] }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "time below min",
+			conf: Config{
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter,min=2000-01-01T00:00:00Z"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": "1999-12-31T00:00:00Z"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "json array index",
+			conf: Config{
+				Model: new(struct {
+					Scores json.RawMessage `rql:"filter,json"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "scores.0": { "$gt": 90 } },
+						{ "scores.1": "a8m" },
+						{ "scores.2.0": { "$in": [true] } },
+						{ "scores.3": { "$null": true } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "((scores->>0)::numeric > ? OR scores->>1 = ? OR (scores#>>'{2,0}')::boolean IN (?) OR scores->>3 IS NULL)",
+				FilterArgs: []interface{}{90.0, "a8m", true},
+			},
+		},
+		{
+			name: "json object paths",
+			conf: Config{
+				Model: new(struct {
+					Metadata map[string]interface{} `rql:"filter,json"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "metadata.tier": "gold" },
+						{ "metadata.limits.seats": { "$between": [1, 5] } },
+						{ "metadata.items.0.name": { "$neq": "a8m" } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(metadata->>'tier' = ? OR (metadata#>>'{limits,seats}')::numeric BETWEEN ? AND ? OR metadata#>>'{items,0,name}' <> ?)",
+				FilterArgs: []interface{}{"gold", 1.0, 5.0, "a8m"},
+			},
+		},
+		{
+			name: "json object path with invalid key",
+			conf: Config{
+				Model: new(struct {
+					Metadata map[string]interface{} `rql:"filter,json"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"metadata.tier'--": "gold"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "json array empty segment",
+			conf: Config{
+				Model: new(struct {
+					Scores json.RawMessage `rql:"filter,json"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"scores..0": 1
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "json array index on non-json field",
+			conf: Config{
+				Model: new(struct {
+					Scores []int `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"scores.0": 1
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "json array index with mixed values",
+			conf: Config{
+				Model: new(struct {
+					Scores json.RawMessage `rql:"filter,json"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"scores.0": { "$in": [1, "a8m"] }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "json array index with object value",
+			conf: Config{
+				Model: new(struct {
+					Scores json.RawMessage `rql:"filter,json"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"scores.0": { "$eq": { "a": 1 } }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "default filter and client filter",
+			conf: Config{
+				Model: new(struct {
+					Name      string     `rql:"filter"`
+					Age       int        `rql:"filter"`
+					DeletedAt *time.Time `rql:"filter"`
+				}),
+				DefaultFilter: map[string]interface{}{
+					"deleted_at": map[string]interface{}{"$null": true},
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "name": "a8m" },
+						{ "age": { "$gt": 10 } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "deleted_at IS NULL AND (name = ? OR age > ?)",
+				FilterArgs: []interface{}{"a8m", 10},
+			},
+		},
+		{
+			name: "default filter only",
+			conf: Config{
+				Model: new(struct {
+					Name      string     `rql:"filter"`
+					Age       int        `rql:"filter"`
+					DeletedAt *time.Time `rql:"filter"`
+				}),
+				DefaultFilter: map[string]interface{}{
+					"deleted_at": map[string]interface{}{"$null": true},
+				},
+			},
+			input: []byte(`{}`),
+			wantOut: &Params{
+				Limit:     25,
+				FilterExp: "deleted_at IS NULL",
+			},
+		},
+		{
+			name: "client filter only",
+			conf: Config{
+				Model: new(struct {
+					Name      string     `rql:"filter"`
+					Age       int        `rql:"filter"`
+					DeletedAt *time.Time `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "a8m"
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "name = ?",
+				FilterArgs: []interface{}{"a8m"},
+			},
+		},
+		{
+			name: "neither default filter nor client filter",
+			conf: Config{
+				Model: new(struct {
+					Name      string     `rql:"filter"`
+					Age       int        `rql:"filter"`
+					DeletedAt *time.Time `rql:"filter"`
+				}),
+			},
+			input: []byte(`{}`),
+			wantOut: &Params{
+				Limit: 25,
+			},
+		},
+		{
+			name: "default filter is not negated",
+			conf: Config{
+				Model: new(struct {
+					Name      string     `rql:"filter"`
+					Age       int        `rql:"filter"`
+					DeletedAt *time.Time `rql:"filter"`
+				}),
+				DefaultFilter: map[string]interface{}{
+					"deleted_at": map[string]interface{}{"$null": true},
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "a8m"
+				},
+				"negate": true
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "deleted_at IS NULL AND NOT (name = ?)",
+				FilterArgs: []interface{}{"a8m"},
+			},
+		},
+		{
+			name: "overlaps range",
+			conf: Config{
+				Model: new(struct {
+					Name     string    `rql:"filter"`
+					StartsAt time.Time `rql:"filter"`
+					EndsAt   time.Time `rql:"filter"`
+				}),
+				Ranges: map[string][2]string{"period": {"starts_at", "ends_at"}},
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "a8m",
+					"period": {
+						"$overlaps": { "start": "2018-01-01T00:00:00Z", "end": "2018-02-01T00:00:00Z" }
+					}
+				}
+			}`),
+			wantOut: &Params{
+				Limit:     25,
+				FilterExp: "name = ? AND (starts_at, ends_at) OVERLAPS (?, ?)",
+				FilterArgs: []interface{}{
+					"a8m",
+					mustParseTime(time.RFC3339, "2018-01-01T00:00:00Z"),
+					mustParseTime(time.RFC3339, "2018-02-01T00:00:00Z"),
+				},
+			},
+		},
+		{
+			name: "overlaps range invalid bound",
+			conf: Config{
+				Model: new(struct {
+					StartsAt time.Time `rql:"filter"`
+					EndsAt   time.Time `rql:"filter"`
+				}),
+				Ranges: map[string][2]string{"period": {"starts_at", "ends_at"}},
+			},
+			input: []byte(`{
+				"filter": {
+					"period": { "$overlaps": { "start": "2018-01-01T00:00:00Z", "end": "a8m" } }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "overlaps range missing bound",
+			conf: Config{
+				Model: new(struct {
+					StartsAt time.Time `rql:"filter"`
+					EndsAt   time.Time `rql:"filter"`
+				}),
+				Ranges: map[string][2]string{"period": {"starts_at", "ends_at"}},
+			},
+			input: []byte(`{
+				"filter": {
+					"period": { "$overlaps": { "start": "2018-01-01T00:00:00Z" } }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "overlaps on a non range field",
+			conf: Config{
+				Model: new(struct {
+					StartsAt time.Time `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"starts_at": { "$overlaps": { "start": "2018-01-01T00:00:00Z", "end": "2018-02-01T00:00:00Z" } }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch time multiple layouts",
+			conf: Config{
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter,layout=RFC3339|2006-01-02"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": { "$gt": "Thu May 23 09:30:06 IDT 2000" }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch time unix layout",
+			conf: Config{
+				Model: new(struct {
+					CreatedAt time.Time `rql:"filter,layout=UnixDate"`
+				}),
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": { "$gt": "2006-01-02 15:04" }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch int type 1",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"age": "a8m",
+					"name": 10
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch int type 2",
+			conf: Config{
+				Model: struct {
+					Age int `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"age": 1.1
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "custom time function",
+			conf: Config{
+				Model: struct {
+					CreatedAt time.Time `rql:"filter,max=1800000000000"`
+				}{},
+				TimeFn: func(f *FieldMeta, raw string) (time.Time, error) {
+					ms, err := strconv.ParseInt(raw, 10, 64)
+					if err != nil {
+						return time.Time{}, err
+					}
+					return time.Unix(0, ms*int64(time.Millisecond)).UTC(), nil
+				},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": { "$gt": "1700000000000" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "created_at > ?",
+				FilterArgs: []interface{}{time.Unix(1700000000, 0).UTC()},
+			},
+		},
+		{
+			name: "custom time function with invalid value",
+			conf: Config{
+				Model: struct {
+					CreatedAt time.Time `rql:"filter"`
+				}{},
+				TimeFn: func(f *FieldMeta, raw string) (time.Time, error) {
+					ms, err := strconv.ParseInt(raw, 10, 64)
+					return time.Unix(0, ms*int64(time.Millisecond)), err
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": "2018-01-14T06:05:48.839Z"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "custom time function with out of bounds value",
+			conf: Config{
+				Model: struct {
+					CreatedAt time.Time `rql:"filter,max=1800000000000"`
+				}{},
+				TimeFn: func(f *FieldMeta, raw string) (time.Time, error) {
+					ms, err := strconv.ParseInt(raw, 10, 64)
+					return time.Unix(0, ms*int64(time.Millisecond)), err
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": { "$lt": "1900000000000" }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "whole float on int field",
+			conf: Config{
+				Model: struct {
+					Age  int  `rql:"filter"`
+					Size uint `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"age": 10.0,
+					"size": { "$in": [1.0, 2e1] }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "age = ? AND size IN (?, ?)",
+				FilterArgs: []interface{}{10, 1, 20},
+			},
+		},
+		{
+			name: "big numbers",
+			conf: Config{
+				Model: struct {
+					Balance *big.Int  `rql:"filter"`
+					Rate    big.Float `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"balance": { "$gt": "123456789012345678901234567890", "$lte": 1e20 },
+					"rate": { "$between": [0.5, "1.000000000000000000000001"] }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(balance > ? AND balance <= ?) AND rate BETWEEN ? AND ?",
+				FilterArgs: []interface{}{"123456789012345678901234567890", "100000000000000000000", "0.5", "1.000000000000000000000001"},
+			},
+		},
+		{
+			name: "mismatch big int type",
+			conf: Config{
+				Model: struct {
+					Balance big.Int `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"balance": { "$in": [1, 1.5] }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch big float type",
+			conf: Config{
+				Model: struct {
+					Rate *big.Float `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"rate": "Inf"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch string type",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"name": 10
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch uint type 1",
+			conf: Config{
+				Model: struct {
+					Age uint `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"age": "a8m"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch uint type 2",
+			conf: Config{
+				Model: struct {
+					Age uint `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"age": -1
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch time type 1",
+			conf: Config{
+				Model: struct {
+					CreatedAt time.Time `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": "Sunday?"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch time type 2",
+			conf: Config{
+				Model: struct {
+					CreatedAt time.Time `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"created_at": 12736186894
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch bool type",
+			conf: Config{
+				Model: struct {
+					Admin bool `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"admin": "false"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "coerce string bool",
+			conf: Config{
+				Model: struct {
+					Admin  bool         `rql:"filter"`
+					Active sql.NullBool `rql:"filter"`
+				}{},
+				CoerceStringBool: true,
+				DefaultLimit:     25,
+			},
+			input: []byte(`{
+				"filter": {
+					"admin": "false",
+					"active": { "$neq": "true" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "active <> ? AND admin = ?",
+				FilterArgs: []interface{}{true, false},
+			},
+		},
+		{
+			name: "coerce invalid string bool",
+			conf: Config{
+				Model: struct {
+					Admin bool `rql:"filter"`
+				}{},
+				CoerceStringBool: true,
+			},
+			input: []byte(`{
+				"filter": {
+					"admin": "yes"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "mismatch float type",
+			conf: Config{
+				Model: struct {
+					Age float32 `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"age": "13"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "unrecognized fields",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"age": "a8m"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "field is not sortable",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"sort": ["name"]
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "invalid operation",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"name": {
+						"$gt": 10
+					}
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "unrecognized operation",
+			conf: Config{
+				Model: struct {
+					Name string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"name": {
+						"$regex": ".*"
+					}
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "max filter fields",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+					City string `rql:"filter"`
+				}{},
+				DefaultLimit:    25,
+				MaxFilterFields: 2,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": { "$gt": 10, "$lt": 20 } },
+						{ "age": 30 }
+					],
+					"name": { "$like": "a%" }
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "((age > ? AND age < ?) OR age = ?) AND name LIKE ?",
+				FilterArgs: []interface{}{10, 20, 30, "a%"},
+			},
+		},
+		{
+			name: "exceeding max filter fields",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+					City string `rql:"filter"`
+				}{},
+				MaxFilterFields: 2,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [{ "age": 10 }, { "city": "TLV" }],
+					"name": "a8m"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "value transformer",
+			conf: Config{
+				Model: struct {
+					Email  string `rql:"filter"`
+					Status string `rql:"filter"`
+				}{},
+				DefaultLimit: 25,
+				ValueFn: func(f *FieldMeta, v interface{}) (interface{}, error) {
+					switch f.Name {
+					case "email":
+						return strings.ToLower(v.(string)), nil
+					case "status":
+						n, ok := map[string]int{"active": 1, "blocked": 2}[v.(string)]
+						if !ok {
+							return nil, fmt.Errorf("unknown status %q", v)
+						}
+						return n, nil
+					}
+					return v, nil
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "email": "A8M@Example.com" },
+						{ "status": { "$in": ["active", "blocked"] } },
+						{ "status": { "$neq": "blocked" } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(email = ? OR status IN (?, ?) OR status <> ?)",
+				FilterArgs: []interface{}{"a8m@example.com", 1, 2, 2},
+			},
+		},
+		{
+			name: "value transformer error",
+			conf: Config{
+				Model: struct {
+					Status string `rql:"filter"`
+				}{},
+				ValueFn: func(f *FieldMeta, v interface{}) (interface{}, error) {
+					return nil, fmt.Errorf("unknown status %q", v)
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"status": "deleted"
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "max filter depth",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit:   25,
+				MaxFilterDepth: 2,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "$not": { "age": 10 } },
+						{ "name": "a8m" }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(NOT (age = ?) OR name = ?)",
+				FilterArgs: []interface{}{10, "a8m"},
+			},
+		},
+		{
+			name: "exceeding max filter depth",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}{},
+				MaxFilterDepth: 2,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "$and": [{ "$or": [{ "age": 10 }] }] },
+						{ "name": "a8m" }
+					]
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "max filter conditions",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit:        25,
+				MaxFilterConditions: 3,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": { "$between": [1, 5] } },
+						{ "age": 10 },
+						{ "name": "a8m" }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(age BETWEEN ? AND ? OR age = ? OR name = ?)",
+				FilterArgs: []interface{}{1, 5, 10, "a8m"},
+			},
+		},
+		{
+			name: "exceeding max filter conditions",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}{},
+				MaxFilterConditions: 3,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": 10 },
+						{ "age": 20 },
+						{ "name": "a8m" },
+						{ "name": "foo" }
+					]
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "default filter exceeding the filter limits",
+			conf: Config{
+				Model: struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}{},
+				DefaultLimit:        25,
+				MaxFilterConditions: 2,
+				MaxFilterDepth:      1,
+				DefaultFilter: map[string]interface{}{
+					"$or": []interface{}{
+						map[string]interface{}{"age": map[string]interface{}{"$gt": 10.0}},
+						map[string]interface{}{"age": map[string]interface{}{"$lt": 0.0}},
+						map[string]interface{}{"name": "a8m"},
+					},
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"age": 20,
+					"name": "foo"
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(age > ? OR age < ? OR name = ?) AND age = ? AND name = ?",
+				FilterArgs: []interface{}{10, 0, "a8m", 20, "foo"},
+			},
+		},
+		{
+			name: "limit and offset",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"limit": 10,
+				"offset": 4
+			}`),
+			wantOut: &Params{
+				Limit:  10,
+				Offset: 4,
+			},
+		},
+		{
+			name: "page and page size",
+			conf: Config{
+				Model:        struct{}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"page": 3,
+				"pageSize": 10
+			}`),
+			wantOut: &Params{
+				Limit:  10,
+				Offset: 20,
+			},
+		},
+		{
+			name: "page with limit",
+			conf: Config{
+				Model:        struct{}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"page": 2,
+				"limit": 5
+			}`),
+			wantOut: &Params{
+				Limit:  5,
+				Offset: 5,
+			},
+		},
+		{
+			name: "page with default limit",
+			conf: Config{
+				Model:        struct{}{},
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"page": 1
+			}`),
+			wantOut: &Params{
+				Limit:  25,
+				Offset: 0,
+			},
+		},
+		{
+			name: "offset within max value",
+			conf: Config{
+				Model:          struct{}{},
+				DefaultLimit:   25,
+				OffsetMaxValue: 100,
+			},
+			input: []byte(`{
+				"offset": 100
+			}`),
+			wantOut: &Params{
+				Limit:  25,
+				Offset: 100,
+			},
+		},
+		{
+			name: "offset exceeds max value",
+			conf: Config{
+				Model:          struct{}{},
+				OffsetMaxValue: 100,
+			},
+			input: []byte(`{
+				"offset": 101
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "page offset exceeds max value",
+			conf: Config{
+				Model:          struct{}{},
+				OffsetMaxValue: 100,
+			},
+			input: []byte(`{
+				"page": 3,
+				"pageSize": 60
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "page overflows offset",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"page": 922337203685477583,
+				"limit": 10
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "conflicting limit and page size",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"limit": 10,
+				"pageSize": 10
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "conflicting offset and page",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"offset": 10,
+				"page": 2
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "conflicting limit, page size, offset and page",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"limit": 10,
+				"pageSize": 10,
+				"offset": 10,
+				"page": 2
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "invalid page",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"page": -1
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "invalid page size",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"pageSize": 1000
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "invalid offset",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"limit": 10,
+				"offset": -14
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "invalid limit 1",
+			conf: Config{
+				Model: struct{}{},
+			},
+			input: []byte(`{
+				"limit": -10
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "invalid limit 2",
+			conf: Config{
+				Model:         struct{}{},
+				LimitMaxValue: 100,
+			},
+			input: []byte(`{
+				"limit": 200
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "support name struct opt",
+			conf: Config{
+				Model: struct {
+					SomeName string `rql:"filter,name=someName,column=some_name"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"someName": {
+						"$eq": "someName"
+					}
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "some_name = ?",
+				FilterArgs: []interface{}{"someName"},
+			},
+		},
+		{
+			name: "redirect to denormalized column",
+			conf: Config{
+				Model: struct {
+					Name    string `rql:"filter,sort"`
+					Address struct {
+						City string `rql:"filter,sort,via=address_city_denorm"`
+					}
+				}{},
+				FieldSep:     ".",
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "foo",
+					"address.city": { "$like": "TLV%" }
+				},
+				"select": ["address.city"],
+				"sort": ["-address.city"]
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "name = ? AND address_city_denorm LIKE ?",
+				FilterArgs: []interface{}{"foo", "TLV%"},
+				Select:     "address_city_denorm",
+				Sort:       "address_city_denorm desc",
+			},
+		},
+		{
+			name: "backwards compatibility to error with mismatching keys and no namefn",
+			conf: Config{
+				Model: struct {
+					SomeName string `rql:"filter"`
+				}{},
+			},
+			input: []byte(`{
+				"filter": {
+					"someName": {
+						"$eq": "someName"
+					}
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "test nameFn works",
+			conf: Config{
+				Model: struct {
+					SomeName string `rql:"filter,name=someName,column=some_name"`
+				}{},
+				NameFn: Column,
+			},
+			input: []byte(`{
+				"filter": {
+					"someName": {
+						"$eq": "someName"
+					}
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "some_name = ?",
+				FilterArgs: []interface{}{"someName"},
+			},
+		},
+		{
+			name: "custom db symbols",
+			conf: Config{
+				Model: struct {
+					ID           string `rql:"filter"`
+					FullName     string `rql:"filter"`
+					HTTPUrl      string `rql:"filter"`
+					NestedStruct struct {
+						UUID string `rql:"filter"`
+					}
+				}{},
+				FieldSep: ".",
+				GetDBStatement: func(o Op, f *FieldMeta) (string, string) {
+					if o == EQ {
+						return "eq", "%v %v %v"
+					}
+					return opFormat[o], "%v %v %v"
+				},
+				GetDBDir: func(d Direction) string {
+					if d == ASC {
+						return "ASC"
+					}
+					return "DESC"
+				},
+			},
+			input: []byte(`{
+				"filter": {
+					"id": "id",
+					"full_name": "full_name",
+					"http_url": "http_url",
+					"nested_struct.uuid": "uuid"
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "id eq ? AND full_name eq ? AND http_url eq ? AND nested_struct_uuid eq ?",
+				FilterArgs: []interface{}{"id", "full_name", "http_url", "uuid"},
+				Sort:       "",
+			},
+		},
+		{
+			name: "alternate parameter symbol test",
+			conf: Config{
+				Model: new(struct {
+					Age     int    `rql:"filter"`
+					Name    string `rql:"filter"`
+					Address string `rql:"filter"`
+					Other   string
+				}),
+				ParamSymbol:  "@",
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+					"filter": {
+						"name": "foo",
+						"age": 12,
+						"$or": [
+							{ "address": "DC" },
+							{ "address": "Marvel" }
+						],
+						"$and": [
+							{ "age": { "$neq": 10} },
+							{ "age": { "$neq": 20} },
+							{ "$or": [{ "age": 11 }, {"age": 10}] }
+						]
+					}
+				}`),
+			wantOut: &Params{
+				Limit:       25,
+				FilterExp:   "name = @ AND age = @ AND (address = @ OR address = @) AND (age <> @ AND age <> @ AND (age = @ OR age = @))",
+				FilterArgs:  []interface{}{"foo", 12, "DC", "Marvel", 10, 20, 11, 10},
+				ParamSymbol: "@",
+			},
+		},
+		{
+			name: "positional parameter symbol test",
+			conf: Config{
+				Model: new(struct {
+					Age     int    `rql:"filter"`
+					Name    string `rql:"filter"`
+					Address string `rql:"filter"`
+					Other   string
+				}),
+				ParamSymbol:      "$",
+				PositionalParams: true,
+				DefaultLimit:     25,
+			},
+			input: []byte(`{
+				"filter": {
+					"name": "foo",
+					"age": 12,
+					"$or": [
+						{ "address": "DC" },
+						{ "address": "Marvel" }
+					],
+					"$and": [
+						{ "age": { "$neq": 10} },
+						{ "age": { "$neq": 20} },
+						{ "$or": [{ "age": 11 }, {"age": 10}] }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:            25,
+				FilterExp:        "(age <> $1 AND age <> $2 AND (age = $3 OR age = $4)) AND (address = $5 OR address = $6) AND age = $7 AND name = $8",
+				FilterArgs:       []interface{}{10, 20, 11, 10, "DC", "Marvel", 12, "foo"},
+				ParamSymbol:      "$",
+				PositionalParams: true,
+			},
+		},
+		{
+			name: "oracle placeholders",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter"`
+					Name string `rql:"filter"`
+				}),
+				Dialect:      DialectOracle,
+				DefaultLimit: 25,
+			},
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "name": "foo" },
+						{ "age": { "$gte": 10, "$lte": 20 } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:            25,
+				FilterExp:        "(name = :1 OR (age >= :2 AND age <= :3))",
+				FilterArgs:       []interface{}{"foo", 10, 20},
+				ParamSymbol:      ":",
+				PositionalParams: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.Log = t.Logf
+			p, err := NewParser(tt.conf)
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			out, err := p.Parse(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			assertParams(t, out, tt.wantOut)
+		})
+	}
+}
+
+// AssertQueryEqual tests if two query input are equal.
+// TODO: improve this in the future.
+func assertParams(t *testing.T, got *Params, want *Params) {
+	if got == nil && want == nil {
+		return
+	}
+	if got.Limit != want.Limit {
+		t.Fatalf("limit: got: %v want %v", got.Limit, want.Limit)
+	}
+	if got.Offset != want.Offset {
+		t.Fatalf("offset: got: %v want %v", got.Limit, want.Limit)
+	}
+	if got.Sort != want.Sort {
+		t.Fatalf("sort: got: %q want %q", got.Sort, want.Sort)
+	}
+	if got.Select != want.Select {
+		t.Fatalf("select: got: %q want %q", got.Select, want.Select)
+	}
+	if got.Group != want.Group {
+		t.Fatalf("group: got: %q want %q", got.Group, want.Group)
+	}
+	if got.CursorExp != want.CursorExp {
+		t.Fatalf("cursor expr: got: %q want %q", got.CursorExp, want.CursorExp)
+	}
+	if !reflect.DeepEqual(got.CursorArgs, want.CursorArgs) {
+		t.Fatalf("cursor args: got: %v want %v", got.CursorArgs, want.CursorArgs)
+	}
+	if got.Distinct != want.Distinct {
+		t.Fatalf("distinct: got: %v want %v", got.Distinct, want.Distinct)
+	}
+	if !reflect.DeepEqual(got.Joins, want.Joins) {
+		t.Fatalf("joins: got: %q want %q", got.Joins, want.Joins)
+	}
+	if !equalExp(got.FilterExp, want.FilterExp, got.ParamSymbol, got.PositionalParams) || !equalExp(want.FilterExp, got.FilterExp, want.ParamSymbol, want.PositionalParams) {
+		t.Fatalf("filter expr:\n\tgot: %q\n\twant %q", got.FilterExp, want.FilterExp)
+	}
+	err := deepEqualIgnoreOrder(got.FilterArgs, want.FilterArgs)
+	if err != nil {
+		t.Fatalf("filter expr:\n\tgot: %v\n\twant %v.\n%v", got.FilterArgs, want.FilterArgs, err.Error())
+	}
+}
+
+func equalExp(e1, e2 string, pexp string, pos bool) bool {
+	if pexp == "" {
+		pexp = "?"
+	}
+	s1, s2 := split(e1, pexp, pos), split(e2, pexp, pos)
+	for i := range s1 {
+		var found bool
+		for j := range s2 {
+			// if it is a start of conjunction.
+			if s1[i][0] == '(' && s2[j][0] == '(' {
+				found = equalExp(s1[i][1:len(s1[i])-1], s2[j][1:len(s2[j])-1], pexp, pos)
+			} else {
+				found = s1[i] == s2[j]
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func split(e string, pexp string, pos bool) []string {
+	var s []string
+	for len(e) > 0 {
+		if e[0] == '(' {
+			end := closingParen(e) + 1
+			s = append(s, e[:end])
+			e = e[end:]
+		} else {
+			end := strings.IndexByte(e, pexp[0]) + 1
+			// expressions without a placeholder (e.g. "IS NULL") end at the next conjunction.
+			for _, sep := range []string{" AND ", " OR "} {
+				if i := strings.Index(e, sep); i > 0 && (end == 0 || i < end-1) {
+					end = i
+				}
+			}
+			if end == 0 {
+				end = len(e)
+			}
+			if pos {
+				for {
+					if end >= len(e) {
+						break
+					} else if unicode.IsDigit(rune(e[end])) {
+						end++
+					} else {
+						break
+					}
+				}
+			}
+			s = append(s, e[:end])
+			e = e[end:]
+		}
+		e = strings.TrimPrefix(e, " AND ")
+		e = strings.TrimPrefix(e, " OR ")
+	}
+	return s
+}
+
+// closingParen returns the index of the parenthesis that closes the one at the start of e.
+func closingParen(e string) int {
+	var depth int
+	for i := 0; i < len(e); i++ {
+		switch e[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(e) - 1
+}
+
+func mustParseTime(layout, s string) time.Time {
+	t, _ := time.Parse(layout, s)
+
+	return t
+}
+
+func TestSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     Config
+		input    []byte
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "default dialect",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"sort": ["-age"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantSQL: "WHERE name = ? ORDER BY age desc LIMIT 10 OFFSET 20",
+		},
+		{
+			name: "group by",
+			conf: Config{
+				Model: new(struct {
+					Status string `rql:"filter,group"`
+					Region string `rql:"filter,sort,group"`
+				}),
+			},
+			input: []byte(`{
+				"filter": { "status": "active" },
+				"group": ["status", "region"],
+				"sort": ["region"]
+			}`),
+			wantSQL: "WHERE status = ? GROUP BY status, region ORDER BY region LIMIT 25",
+		},
+		{
+			name: "default dialect without filter and offset",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter,sort"`
+				}),
+			},
+			input:   []byte(`{}`),
+			wantSQL: "LIMIT 25",
+		},
+		{
+			name: "oracle dialect",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+				Dialect: DialectOracle,
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"sort": ["age"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantSQL: "WHERE name = :1 ORDER BY age OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name: "joins",
+			conf: Config{
+				Model: new(struct {
+					City string `rql:"filter,column=addresses.city,join=JOIN addresses ON addresses.user_id = users.id"`
+				}),
+			},
+			input: []byte(`{
+				"filter": { "addresses.city": "TLV" }
+			}`),
+			wantSQL: "JOIN addresses ON addresses.user_id = users.id WHERE addresses.city = ? LIMIT 25",
+		},
+		{
+			name: "pagination params",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+				PaginationParams: true,
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantSQL:  "WHERE name = ? LIMIT ? OFFSET ?",
+			wantArgs: []interface{}{"foo", 10, 20},
+		},
+		{
+			name: "positional pagination params",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+				ParamSymbol:      "$",
+				PositionalParams: true,
+				PaginationParams: true,
+			},
+			input: []byte(`{
+				"filter": { "$or": [{ "name": "foo" }, { "age": 2 }] },
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantSQL:  "WHERE (name = $1 OR age = $2) LIMIT $3 OFFSET $4",
+			wantArgs: []interface{}{"foo", 2, 10, 20},
+		},
+		{
+			name: "positional pagination params without offset",
+			conf: Config{
+				Model: new(struct {
+					Age int `rql:"filter,sort"`
+				}),
+				ParamSymbol:      "$",
+				PositionalParams: true,
+				PaginationParams: true,
+			},
+			input:    []byte(`{}`),
+			wantSQL:  "LIMIT $1",
+			wantArgs: []interface{}{25},
+		},
+		{
+			name: "oracle pagination params",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+				Dialect:          DialectOracle,
+				PaginationParams: true,
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantSQL:  "WHERE name = :1 OFFSET :2 ROWS FETCH NEXT :3 ROWS ONLY",
+			wantArgs: []interface{}{"foo", 20, 10},
+		},
+		{
+			name: "unlimited with offset",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+				AllowUnlimited:   true,
+				PaginationParams: true,
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"sort": ["age"],
+				"offset": 10
+			}`),
+			wantSQL:  "WHERE name = ? ORDER BY age OFFSET ?",
+			wantArgs: []interface{}{"foo", 10},
+		},
+		{
+			name: "unlimited without offset",
+			conf: Config{
+				Model: new(struct {
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+				AllowUnlimited: true,
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"sort": ["age"]
+			}`),
+			wantSQL: "WHERE name = ? ORDER BY age",
+		},
+		{
+			name: "unlimited with a given limit",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter"`
+				}),
+				AllowUnlimited: true,
+			},
+			input: []byte(`{
+				"limit": 10,
+				"offset": 10
+			}`),
+			wantSQL: "LIMIT 10 OFFSET 10",
+		},
+		{
+			name: "oracle unlimited",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter"`
+				}),
+				Dialect:        DialectOracle,
+				AllowUnlimited: true,
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"offset": 10
+			}`),
+			wantSQL: "WHERE name = :1 OFFSET 10 ROWS",
+		},
+		{
+			name: "escaped like cast",
+			conf: Config{
+				Model: new(struct {
+					ID   int    `rql:"filter,likecast"`
+					Code string `rql:"filter"`
+				}),
+				EscapeLike: true,
+			},
+			input: []byte(`{
+				"filter": {
+					"id": { "$like": "12%" },
+					"code": { "$ilike": "a%" }
+				}
+			}`),
+			wantSQL:  `WHERE code ILIKE ? ESCAPE '\' AND CAST(id AS TEXT) LIKE ? ESCAPE '\' LIMIT 25`,
+			wantArgs: []interface{}{"a%", "12%"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.Log = t.Logf
+			p, err := NewParser(tt.conf)
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			out, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if got := out.SQL(); got != tt.wantSQL {
+				t.Fatalf("sql:\n\tgot: %q\n\twant %q", got, tt.wantSQL)
+			}
+			if got := out.SQLArgs(); tt.wantArgs != nil && !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Fatalf("sql args:\n\tgot: %v\n\twant %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCountExp(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     Config
+		input    []byte
+		wantExp  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "filter without pagination",
+			conf: Config{
+				Model: new(struct {
+					ID   int    `rql:"filter,sort"`
+					Age  int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": { "age": { "$gt": 10 } },
+				"sort": ["-age"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantExp:  "WHERE age > ?",
+			wantArgs: []interface{}{10},
+		},
+		{
+			name: "cursor is excluded",
+			conf: Config{
+				Model: new(struct {
+					ID   int    `rql:"filter,sort"`
+					Name string `rql:"filter"`
+				}),
+			},
+			input: []byte(`{
+				"filter": { "name": "foo" },
+				"sort": ["id"],
+				"after": { "id": 1000 }
+			}`),
+			wantExp:  "WHERE name = ?",
+			wantArgs: []interface{}{"foo"},
+		},
+		{
+			name: "joins",
+			conf: Config{
+				Model: new(struct {
+					City string `rql:"filter,column=addresses.city,join=JOIN addresses ON addresses.user_id = users.id"`
+				}),
+			},
+			input: []byte(`{
+				"filter": { "addresses.city": "TLV" },
+				"limit": 5
+			}`),
+			wantExp:  "JOIN addresses ON addresses.user_id = users.id WHERE addresses.city = ?",
+			wantArgs: []interface{}{"TLV"},
+		},
+		{
+			name: "without filter",
+			conf: Config{
+				Model: new(struct {
+					Name string `rql:"filter,sort"`
+				}),
+			},
+			input: []byte(`{
+				"sort": ["name"],
+				"limit": 5
+			}`),
+			wantExp:  "",
+			wantArgs: []interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.Log = t.Logf
+			p, err := NewParser(tt.conf)
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			out, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			exp, args := out.CountExp()
+			if exp != tt.wantExp {
+				t.Fatalf("count expr:\n\tgot: %q\n\twant %q", exp, tt.wantExp)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("count args:\n\tgot: %v\n\twant %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestGetFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    Config
+		wantOut []*Field
+	}{
+		{
+			name: "get fields",
+			conf: Config{
+				Model: struct {
+					SomeName string `rql:"filter"`
+				}{},
+			},
+			wantOut: []*Field{
+				&Field{
+					FieldMeta: &FieldMeta{
+						Name:         "some_name",
+						Column:       "some_name",
+						Sortable:     false,
+						Filterable:   true,
+						Kind:         reflect.String,
+						AvailableOps: []string{"$eq", "$neq", "$lt", "$lte", "$gt", "$gte", "$like", "$ilike", "$startswith", "$endswith", "$includes", "$contains", "$between", "$in", "$nin"},
+					},
+				},
+			},
+		},
+		{
+			name: "resolved columns, ops and kinds",
+			conf: Config{
+				Model: struct {
+					ID        int        `rql:"filter,sort,ops=eq|in"`
+					Name      string     `rql:"sort,column=full_name"`
+					CreatedAt *time.Time `rql:"filter"`
+				}{},
+			},
+			wantOut: []*Field{
+				&Field{
+					FieldMeta: &FieldMeta{
+						Name:         "created_at",
+						Column:       "created_at",
+						Filterable:   true,
+						Kind:         reflect.Struct,
+						AvailableOps: []string{"$eq", "$neq", "$lt", "$lte", "$gt", "$gte", "$between", "$in", "$nin", "$null"},
+					},
+				},
+				&Field{
+					FieldMeta: &FieldMeta{
+						Name:     "full_name",
+						Column:   "full_name",
+						Sortable: true,
+						Kind:     reflect.String,
+					},
+				},
+				&Field{
+					FieldMeta: &FieldMeta{
+						Name:         "id",
+						Column:       "id",
+						Sortable:     true,
+						Filterable:   true,
+						Kind:         reflect.Int,
+						AvailableOps: []string{"$eq", "$in"},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(tt.conf)
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			out := p.GetFields()
+			assertFieldsEqual(t, out, tt.wantOut)
+		})
+	}
+}
+
+func assertFieldsEqual(t *testing.T, got []*Field, want []*Field) {
+	if len(got) != len(want) {
+		t.Fatalf("got %v, wanted %v", got, want)
+	}
+	for i := 0; i < len(got); i++ {
+		if got[i].Filterable != want[i].Filterable {
+			t.Fatalf("Filterable got:%v want: %v", got[i].Filterable, want[i].Filterable)
+		}
+		if got[i].Sortable != want[i].Sortable {
+			t.Fatalf("Sortable got:%v want: %v", got[i].Sortable, want[i].Sortable)
+		}
+		if got[i].Name != want[i].Name {
+			t.Fatalf("Name got:%v want: %v", got[i].Name, want[i].Name)
+		}
+		if got[i].Column != want[i].Column {
+			t.Fatalf("Column got:%v want: %v", got[i].Column, want[i].Column)
+		}
+		if got[i].Kind != want[i].Kind {
+			t.Fatalf("Kind got:%v want: %v", got[i].Kind, want[i].Kind)
+		}
+		if !reflect.DeepEqual(got[i].AvailableOps, want[i].AvailableOps) {
+			t.Fatalf("AvailableOps got:%v want: %v", got[i].AvailableOps, want[i].AvailableOps)
+		}
+	}
+}
+
+func compareInterface(a, b interface{}) bool {
+	// If either of the values is nil, handle them first.
+	if a == nil && b != nil {
+		return true // consider nil as the smallest value
+	}
+	if a != nil && b == nil {
+		return false
+	}
+	if a == nil && b == nil {
+		return false // doesn't matter which one comes first if both are nil
+	}
+
+	// If they are slices, compare their sorted string representations.
+	if reflect.TypeOf(a).Kind() == reflect.Slice && reflect.TypeOf(b).Kind() == reflect.Slice {
+		return fmt.Sprint(a) < fmt.Sprint(b)
+	}
+
+	// Otherwise, use the regular string representation for comparison.
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+func deepSort(i interface{}) interface{} {
+	switch v := i.(type) {
+	case []interface{}:
+		newSlice := make([]interface{}, len(v))
+		for j, item := range v {
+			newSlice[j] = deepSort(item)
+		}
+		sort.SliceStable(newSlice, func(i, j int) bool {
+			return compareInterface(newSlice[i], newSlice[j])
+		})
+		return newSlice
+	default:
+		return i
+	}
+}
+
+func deepEqualIgnoreOrder(a, b interface{}) error {
+	// Explicitly handle nil cases
+	if (a == nil && b != nil) || (a != nil && b == nil) {
+		return fmt.Errorf("differences found: A=%v, B=%v", a, b)
+	}
+
+	sortedA := deepSort(a)
+	sortedB := deepSort(b)
+	if !reflect.DeepEqual(sortedA, sortedB) {
+		return fmt.Errorf("differences found: A=%v, B=%v", sortedA, sortedB)
+	}
+	return nil
+}
+
+func TestUsedOps(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age       int        `rql:"filter"`
+			Name      string     `rql:"filter"`
+			Address   string     `rql:"filter"`
+			DeletedAt *time.Time `rql:"filter"`
+		}),
+		Log: t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	out, err := p.Parse([]byte(`{
+		"filter": {
+			"name": { "$like": "%foo%" },
+			"deleted_at": { "$null": false },
+			"$or": [
+				{ "age": { "$gt": 10 } },
+				{ "age": { "$lt": 5 } },
+				{ "address": "DC" }
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	want := map[string][]Op{
+		"name":       {LIKE},
+		"deleted_at": {NULL},
+		"age":        {GT, LT},
+		"address":    {EQ},
+	}
+	if !reflect.DeepEqual(out.UsedOps, want) {
+		t.Fatalf("used ops:\n\tgot: %v\n\twant %v", out.UsedOps, want)
+	}
+}
+
+func TestNeedsScan(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			OrgID     int       `rql:"filter"`
+			Name      string    `rql:"filter"`
+			CreatedAt time.Time `rql:"filter"`
+		}),
+		Log: t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	indexes := [][]string{{"org_id", "created_at"}, {}}
+	tests := []struct {
+		name  string
+		input []byte
+		want  bool
+	}{
+		{
+			name:  "indexed equality",
+			input: []byte(`{"filter": {"org_id": 1}}`),
+		},
+		{
+			name:  "indexed range",
+			input: []byte(`{"filter": {"org_id": {"$gt": 1}}}`),
+		},
+		{
+			name:  "unindexed column",
+			input: []byte(`{"filter": {"name": "a8m"}}`),
+			want:  true,
+		},
+		{
+			name:  "not leading column",
+			input: []byte(`{"filter": {"created_at": {"$gt": "2018-01-14T06:05:48.839Z"}}}`),
+			want:  true,
+		},
+		{
+			name:  "unindexed operator",
+			input: []byte(`{"filter": {"org_id": {"$neq": 1}}}`),
+			want:  true,
+		},
+		{
+			name:  "empty filter",
+			input: []byte(`{}`),
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if got := out.NeedsScan(indexes); got != tt.want {
+				t.Fatalf("needs scan: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamedParams(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age  int    `rql:"filter"`
+			Name string `rql:"filter"`
+			City string `rql:"filter,column=addresses.city"`
+		}),
+		NamedParams: true,
+		Log:         t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	out, err := p.Parse([]byte(`{
+		"filter": {
+			"$or": [
+				{ "age": { "$gt": 10 } },
+				{ "name": { "$like": "%foo%" } },
+				{ "age": { "$between": [1, 5] } },
+				{ "addresses.city": "TLV" }
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	wantExp := "(age > :age_1 OR name LIKE :name_2 OR age BETWEEN :age_3 AND :age_4 OR addresses.city = :addresses_city_5)"
+	if out.FilterExp != wantExp {
+		t.Fatalf("filter expr:\n\tgot: %q\n\twant %q", out.FilterExp, wantExp)
+	}
+	wantArgs := map[string]interface{}{
+		"age_1":            10,
+		"name_2":           "%foo%",
+		"age_3":            1,
+		"age_4":            5,
+		"addresses_city_5": "TLV",
+	}
+	if !reflect.DeepEqual(out.FilterNamedArgs, wantArgs) {
+		t.Fatalf("named args:\n\tgot: %v\n\twant %v", out.FilterNamedArgs, wantArgs)
+	}
+	if len(out.FilterArgs) != len(wantArgs) {
+		t.Fatalf("filter args: got %d args, want %d", len(out.FilterArgs), len(wantArgs))
+	}
+}
+
+func TestNamedParamSymbol(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age  int    `rql:"filter"`
+			Name string `rql:"filter"`
+		}),
+		NamedParams:      true,
+		NamedParamSymbol: "@",
+		Log:              t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	out, err := p.Parse([]byte(`{
+		"filter": {
+			"$or": [
+				{ "age": { "$gt": 10 } },
+				{ "age": { "$lt": 5 } },
+				{ "name": "foo" }
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	wantExp := "(age > @age_1 OR age < @age_2 OR name = @name_3)"
+	if out.FilterExp != wantExp {
+		t.Fatalf("filter expr:\n\tgot: %q\n\twant %q", out.FilterExp, wantExp)
+	}
+	wantArgs := map[string]interface{}{
+		"age_1":  10,
+		"age_2":  5,
+		"name_3": "foo",
+	}
+	if !reflect.DeepEqual(out.FilterNamedArgs, wantArgs) {
+		t.Fatalf("named args:\n\tgot: %v\n\twant %v", out.FilterNamedArgs, wantArgs)
+	}
+}
+
+func TestReuseParams(t *testing.T) {
+	input := []byte(`{
+		"filter": {
+			"$or": [
+				{ "name": "a8m" },
+				{ "nickname": "a8m" },
+				{ "age": { "$between": [1, 2] } },
+				{ "age": { "$in": [1, 2] } },
+				{ "score": { "$in": [1, 2] } },
+				{ "age": 1 }
+			]
+		}
+	}`)
+	tests := []struct {
+		name     string
+		reuse    bool
+		wantExp  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "expanded",
+			wantExp:  "(name = $1 OR nickname = $2 OR age BETWEEN $3 AND $4 OR age IN ($5, $6) OR score IN ($7, $8) OR age = $9)",
+			wantArgs: []interface{}{"a8m", "a8m", 1, 2, 1, 2, 1, 2, 1},
+		},
+		{
+			name:     "deduplicated",
+			reuse:    true,
+			wantExp:  "(name = $1 OR nickname = $1 OR age BETWEEN $2 AND $3 OR age IN ($2, $3) OR score IN ($2, $3) OR age = $2)",
+			wantArgs: []interface{}{"a8m", 1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(Config{
+				Model: new(struct {
+					Age      int    `rql:"filter"`
+					Score    int    `rql:"filter"`
+					Name     string `rql:"filter"`
+					Nickname string `rql:"filter"`
+				}),
+				ParamSymbol:      "$",
+				PositionalParams: true,
+				ReuseParams:      tt.reuse,
+				Log:              t.Logf,
+			})
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			out, err := p.Parse(input)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if out.FilterExp != tt.wantExp {
+				t.Fatalf("filter expr:\n\tgot: %q\n\twant %q", out.FilterExp, tt.wantExp)
+			}
+			if !reflect.DeepEqual(out.FilterArgs, tt.wantArgs) {
+				t.Fatalf("filter args:\n\tgot: %v\n\twant %v", out.FilterArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestNegativeUintError(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age uint `rql:"filter"`
+		}),
+		Log: t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	for _, input := range []string{
+		`{"filter": {"age": -1}}`,
+		`{"filter": {"age": {"$lte": -1}}}`,
+		`{"filter": {"age": {"$in": [1, -1]}}}`,
+		`{"filter": {"age": {"$between": [-1, 1]}}}`,
+	} {
+		if _, err := p.Parse([]byte(input)); !errors.Is(err, ErrNegativeUint) {
+			t.Fatalf("input %s: want ErrNegativeUint, got: %v", input, err)
+		}
+	}
+}
+
+func TestParseErrorDetails(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age      int    `rql:"filter,sort"`
+			Name     string `rql:"filter,ops=eq"`
+			Password string `rql:"filter"`
+			Score    int    `rql:"filter,min=0"`
+		}),
+		Log: t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	tests := []struct {
+		input string
+		code  ErrorCode
+		field string
+		value interface{}
+	}{
+		{`{"filter": {"agee": 1}}`, ErrUnknownField, "agee", 1.0},
+		{`{"filter": {"age": "ten"}}`, ErrTypeMismatch, "age", "ten"},
+		{`{"filter": {"name": {"$like": "a%"}}}`, ErrInvalidOp, "name", nil},
+		{`{"filter": {"score": -1}}`, ErrInvalidValue, "score", -1.0},
+		{`{"sort": ["name"]}`, ErrFieldNotAllowed, "name", nil},
+		{`{"limit": 1000}`, ErrLimitExceeded, "limit", 1000},
+		{`{"filter": {"$or": {}}}`, ErrInvalidQuery, "", nil},
+		{`{"filter": 1}`, ErrInvalidQuery, "", nil},
+	}
+	for _, tt := range tests {
+		_, err := p.Parse([]byte(tt.input))
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("input %s: want a *ParseError, got: %v", tt.input, err)
+		}
+		if perr.Code != tt.code || perr.Field != tt.field || !reflect.DeepEqual(perr.Value, tt.value) {
+			t.Fatalf("input %s: got code %q, field %q and value %v, want %q, %q and %v", tt.input, perr.Code, perr.Field, perr.Value, tt.code, tt.field, tt.value)
+		}
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	model := new(struct {
+		Age  uint   `rql:"filter"`
+		Name string `rql:"filter"`
+	})
+	p := MustNewParser(Config{Model: model, CollectErrors: true})
+	_, err := p.Parse([]byte(`{"filter": {"age": -1, "name": 1, "city": "TLV", "$or": [{"age": "ten"}, {"name": "a8m"}]}}`))
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("want ParseErrors, got: %v", err)
+	}
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	if want := []string{"age", "age", "city", "name"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("got errors of fields %v, want %v", fields, want)
+	}
+	if !errors.Is(err, ErrNegativeUint) {
+		t.Fatalf("want the collected errors to wrap ErrNegativeUint, got: %v", err)
+	}
+	// the collected errors are matched by the Is and As methods, that are supported before Go 1.20 as well.
+	if !errs.Is(ErrNegativeUint) || errs.Is(sql.ErrNoRows) {
+		t.Fatalf("want the collected errors to match only the wrapped errors, got: %v", errs)
+	}
+	var eperr *ParseError
+	if !errs.As(&eperr) || eperr != errs[0] {
+		t.Fatalf("want the first collected error, got: %v", eperr)
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Field != "age" || perr.Error() != errs.Error() {
+		t.Fatalf("want a ParseError with the details of the first error, got: %v", err)
+	}
+	// a single error is returned as is.
+	_, err = p.Parse([]byte(`{"filter": {"age": 1, "city": "TLV"}}`))
+	if !errors.As(err, &perr) || errors.As(err, &errs) || perr.Code != ErrUnknownField {
+		t.Fatalf("want a single ParseError, got: %v", err)
+	}
+	// valid queries are not affected.
+	out, err := p.Parse([]byte(`{"filter": {"age": 1, "name": "a8m"}}`))
+	if err != nil || out.FilterExp == "" {
+		t.Fatalf("unexpected result for a valid query: %v, %v", out, err)
+	}
+}
+
+func TestValidateAgainst(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age  int    `rql:"filter,sort"`
+			Name string `rql:"filter,sort"`
+		}),
+		Log: t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	tests := []struct {
+		name        string
+		input       []byte
+		wantMissing []string
+		wantErr     bool
+	}{
+		{
+			name: "valid query",
+			input: []byte(`{
+				"filter": { "age": { "$gt": 10 }, "name": "a8m" },
+				"sort": ["-age"]
+			}`),
+		},
+		{
+			name: "removed fields",
+			input: []byte(`{
+				"filter": {
+					"age": { "$gt": 10 },
+					"city": "TLV",
+					"$or": [{ "name": "a8m" }, { "email": "a8m@example.com" }, { "city": "NYC" }]
+				},
+				"sort": ["-age", "+email", "created_at nullslast"]
+			}`),
+			wantMissing: []string{"city", "created_at", "email"},
+		},
+		{
+			name: "invalid value of existing field",
+			input: []byte(`{
+				"filter": { "age": "ten", "city": "TLV" }
+			}`),
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			input:   []byte(`{"filter": `),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, err := p.ValidateAgainst(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			if !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Fatalf("missing:\n\tgot: %v\n\twant: %v", missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+// testUUID is a UUID type that is similar to uuid.UUID, and implements encoding.TextUnmarshaler.
+type testUUID [16]byte
+
+func (u *testUUID) UnmarshalText(b []byte) error {
+	s := strings.Replace(string(b), "-", "", -1)
+	if len(s) != 32 {
+		return fmt.Errorf("invalid uuid length: %d", len(b))
+	}
+	_, err := hex.Decode(u[:], []byte(s))
+	return err
 }
 
-func equalExp(e1, e2 string, pexp string, pos bool) bool {
-	if pexp == "" {
-		pexp = "?"
+// testVersion is a struct type that implements encoding.TextUnmarshaler.
+type testVersion struct {
+	Major, Minor int
+}
+
+func (v *testVersion) UnmarshalText(b []byte) error {
+	_, err := fmt.Sscanf(string(b), "v%d.%d", &v.Major, &v.Minor)
+	return err
+}
+
+// testStatus is an enum type that implements encoding.TextUnmarshaler and driver.Valuer.
+type testStatus int
+
+func (s *testStatus) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "active":
+		*s = 1
+	case "inactive":
+		*s = 2
+	default:
+		return fmt.Errorf("unknown status %q", b)
 	}
-	s1, s2 := split(e1, pexp, pos), split(e2, pexp, pos)
-	for i := range s1 {
-		var found bool
-		for j := range s2 {
-			// if it is a start of conjunction.
-			if s1[i][0] == '(' && s2[j][0] == '(' {
-				found = equalExp(s1[i][1:len(s1[i])-1], s2[j][1:len(s2[j])-1], pexp, pos)
-			} else {
-				found = s1[i] == s2[j]
-			}
-			if found {
-				break
-			}
+	return nil
+}
+
+func (s testStatus) Value() (driver.Value, error) {
+	return int64(s), nil
+}
+
+// testDecimal is a decimal type that is similar to decimal.Decimal, and implements encoding.TextUnmarshaler,
+// json.Unmarshaler and driver.Valuer. It is ordered by its Cmp method.
+type testDecimal struct {
+	s string
+}
+
+func (d *testDecimal) UnmarshalText(b []byte) error {
+	if _, err := strconv.ParseFloat(string(b), 64); err != nil {
+		return fmt.Errorf("invalid decimal %q", b)
+	}
+	d.s = string(b)
+	return nil
+}
+
+func (d *testDecimal) UnmarshalJSON(b []byte) error {
+	return d.UnmarshalText(bytes.Trim(b, `"`))
+}
+
+func (d testDecimal) Value() (driver.Value, error) {
+	return d.s, nil
+}
+
+func (d testDecimal) Cmp(d1 testDecimal) int {
+	f, _ := strconv.ParseFloat(d.s, 64)
+	f1, _ := strconv.ParseFloat(d1.s, 64)
+	switch {
+	case f < f1:
+		return -1
+	case f > f1:
+		return 1
+	}
+	return 0
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	// composeAcute is an example normalizer that composes only the "e\u0301" sequence. Real applications
+	// would use a full normalization form, i.e. norm.NFC.String of the golang.org/x/text/unicode/norm package.
+	composeAcute := strings.NewReplacer("e\u0301", "\u00e9").Replace
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Cafe string "rql:\"filter,sort,name=cafe\u0301\""
+		}),
+		NormalizeKeyFn: composeAcute,
+		Log:            t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	for _, name := range []string{"caf\u00e9", "cafe\u0301"} {
+		out, err := p.ParseQuery(&Query{
+			Filter: map[string]interface{}{name: "latte"},
+			Sort:   []string{"-" + name},
+		})
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", name, err)
 		}
-		if !found {
-			return false
+		if out.FilterExp != "cafe = ?" || out.Sort != "cafe desc" {
+			t.Fatalf("unexpected output for %q: %q, %q", name, out.FilterExp, out.Sort)
 		}
 	}
-	return true
+	_, err = NewParser(Config{
+		Model: new(struct {
+			Composed   string "rql:\"filter,name=caf\u00e9\""
+			Decomposed string "rql:\"filter,name=cafe\u0301\""
+		}),
+		NormalizeKeyFn: composeAcute,
+		Log:            t.Logf,
+	})
+	if err == nil {
+		t.Fatal("expected an error for colliding field names")
+	}
+	// the keys of the ColumnExpr and the Ranges configs are normalized like the field names.
+	p, err = NewParser(Config{
+		Model: new(struct {
+			Cafe  string    "rql:\"filter,name=cafe\u0301\""
+			Debut time.Time "rql:\"filter,name=de\u0301but\""
+			End   time.Time `rql:"filter"`
+		}),
+		NormalizeKeyFn: composeAcute,
+		ColumnExpr:     map[string]string{"cafe\u0301": "LOWER(cafe)"},
+		Ranges:         map[string][2]string{"se\u0301ance": {"de\u0301but", "end"}},
+		Log:            t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	out, err := p.Parse([]byte(`{
+		"filter": {
+			"caf\u00e9": "latte",
+			"s\u00e9ance": { "$overlaps": { "start": "2018-01-14T06:05:48.839Z", "end": "2018-01-15T06:05:48.839Z" } }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if want := "LOWER(cafe) = ? AND (debut, end) OVERLAPS (?, ?)"; out.FilterExp != want {
+		t.Fatalf("filter expr:\n\tgot: %q\n\twant %q", out.FilterExp, want)
+	}
 }
 
-func split(e string, pexp string, pos bool) []string {
-	var s []string
-	for len(e) > 0 {
-		if e[0] == '(' {
-			end := strings.LastIndexByte(e, ')') + 1
-			s = append(s, e[:end])
-			e = e[end:]
-		} else {
-			end := strings.IndexByte(e, pexp[0]) + 1
-			if pos {
-				for {
-					if end >= len(e) {
-						break
-					} else if unicode.IsDigit(rune(e[end])) {
-						end++
-					} else {
-						break
-					}
-				}
-			}
-			s = append(s, e[:end])
-			e = e[end:]
+func TestParseAST(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age       int        `rql:"filter"`
+			Name      string     `rql:"filter"`
+			DeletedAt *time.Time `rql:"filter"`
+		}),
+		Log: t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	root, err := p.ParseAST([]byte(`{
+		"filter": {
+			"$or": [
+				{ "age": { "$between": [1, 5] } },
+				{ "$not": { "name": "a8m" } },
+				{ "deleted_at": { "$null": false } }
+			]
+		},
+		"negate": true
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if root.Op != NOT || len(root.Children) != 1 {
+		t.Fatalf("root: got %v with %d children, want a NOT group", root.Op, len(root.Children))
+	}
+	and := root.Children[0]
+	if and.Op != AND || len(and.Children) != 1 {
+		t.Fatalf("filter object: got %v with %d children, want an AND group", and.Op, len(and.Children))
+	}
+	or := and.Children[0]
+	if or.Op != OR || len(or.Children) != 3 || or.IsPredicate() {
+		t.Fatalf("$or: got %v with %d children, want an OR group", or.Op, len(or.Children))
+	}
+	between := or.Children[0].Children[0]
+	if !between.IsPredicate() || between.Op != BETWEEN || between.Field.Name != "age" || !reflect.DeepEqual(between.Values, []interface{}{1, 5}) {
+		t.Fatalf("unexpected between predicate: %+v", between)
+	}
+	if between.Exp != "age BETWEEN ? AND ?" {
+		t.Fatalf("between expression: got %q", between.Exp)
+	}
+	not := or.Children[1].Children[0]
+	if not.Op != NOT || len(not.Children) != 1 {
+		t.Fatalf("$not: got %v with %d children, want a NOT group", not.Op, len(not.Children))
+	}
+	if eq := not.Children[0].Children[0]; eq.Op != EQ || eq.Field.Name != "name" || !reflect.DeepEqual(eq.Values, []interface{}{"a8m"}) {
+		t.Fatalf("unexpected eq predicate: %+v", eq)
+	}
+	if null := or.Children[2].Children[0]; null.Op != NOTNULL || null.Field.Name != "deleted_at" || len(null.Values) != 0 {
+		t.Fatalf("unexpected null predicate: %+v", null)
+	}
+	if _, err := p.ParseAST([]byte(`{"filter": {"age": "a8m"}}`)); err == nil {
+		t.Fatal("expected an error for an invalid value")
+	}
+}
+
+func TestRangeConfig(t *testing.T) {
+	model := new(struct {
+		Name     string    `rql:"filter"`
+		StartsAt time.Time `rql:"filter"`
+		EndsAt   time.Time `rql:"filter"`
+	})
+	for _, ranges := range []map[string][2]string{
+		{"period": {"starts_at", "finished_at"}},
+		{"period": {"name", "ends_at"}},
+		{"name": {"starts_at", "ends_at"}},
+	} {
+		if _, err := NewParser(Config{Model: model, Ranges: ranges}); err == nil {
+			t.Fatalf("expected an error for ranges: %v", ranges)
 		}
-		e = strings.TrimPrefix(e, " AND ")
-		e = strings.TrimPrefix(e, " OR ")
 	}
-	return s
 }
 
-func mustParseTime(layout, s string) time.Time {
-	t, _ := time.Parse(layout, s)
+func TestDebugComments(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age       int        `rql:"filter"`
+			Name      string     `rql:"filter"`
+			DeletedAt *time.Time `rql:"filter"`
+		}),
+		DebugComments:    true,
+		PositionalParams: true,
+		ParamSymbol:      "$",
+		Log:              t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	params, err := p.Parse([]byte(`{
+		"filter": {
+			"$or": [
+				{ "age": { "$between": [1, 5] } },
+				{ "$not": { "name": { "$like": "a8m%" } } },
+				{ "deleted_at": { "$null": true } }
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	wantExp := "(age BETWEEN $1 AND $2 OR NOT (name LIKE $3) OR deleted_at IS NULL)"
+	if params.FilterExp != wantExp {
+		t.Fatalf("filter expr:\n\tgot: %q\n\twant %q", params.FilterExp, wantExp)
+	}
+	wantDebug := "(age BETWEEN $1 AND $2 /* field: age op: between */ OR NOT (name LIKE $3 /* field: name op: like */) OR deleted_at IS NULL /* field: deleted_at op: null */)"
+	if params.FilterDebug != wantDebug {
+		t.Fatalf("filter debug:\n\tgot: %q\n\twant %q", params.FilterDebug, wantDebug)
+	}
+	if want := []interface{}{1, 5, "a8m%"}; !reflect.DeepEqual(params.FilterArgs, want) {
+		t.Fatalf("filter args:\n\tgot: %v\n\twant %v", params.FilterArgs, want)
+	}
+	params, err = p.Parse([]byte(`{"filter": {"age": 1}}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if params.FilterDebug != "age = $1 /* field: age op: eq */" {
+		t.Fatalf("unexpected filter debug: %q", params.FilterDebug)
+	}
+}
 
-	return t
+func TestMaxFilterDepthPath(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age  int    `rql:"filter"`
+			Name string `rql:"filter"`
+		}),
+		MaxFilterDepth: 3,
+		Log:            t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	_, err = p.Parse([]byte(`{
+		"filter": {
+			"$or": [
+				{ "name": "a8m" },
+				{ "$and": [{ "age": 1 }, { "$not": { "$or": [{ "age": 10 }] } }] }
+			]
+		}
+	}`))
+	if err == nil {
+		t.Fatal("expected an error for exceeding the max filter depth")
+	}
+	if want := `"$or.1.$and.1.$not.$or"`; !strings.Contains(err.Error(), want) {
+		t.Fatalf("error %q does not contain the exceeded path %s", err, want)
+	}
 }
 
-func TestGetFields(t *testing.T) {
+func TestValidate(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			Age       int       `rql:"filter,sort"`
+			Name      string    `rql:"filter,sort,group"`
+			CreatedAt time.Time `rql:"filter"`
+		}),
+		Log: t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
 	tests := []struct {
 		name    string
-		conf    Config
-		wantOut []*Field
+		input   []byte
+		wantErr bool
 	}{
 		{
-			name: "get fields",
-			conf: Config{
-				Model: struct {
-					SomeName string `rql:"filter"`
-				}{},
-			},
-			wantOut: []*Field{
-				&Field{
-					FieldMeta: &FieldMeta{
-						Name:       "some_name",
-						Sortable:   false,
-						Filterable: true,
-					},
-					// Column:     "some_name",
-					// AvailableOps: []string{"$eq", "$neq", "$lt", "$lte", "$gt", "$gte", "$like"},
+			name: "valid query",
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": { "$gt": 10 } },
+						{ "name": { "$like": "a8m%" } }
+					]
 				},
-			},
+				"sort": ["-age"],
+				"group": ["name"],
+				"after": { "age": 20 },
+				"limit": 10
+			}`),
+		},
+		{
+			name:    "unknown field",
+			input:   []byte(`{"filter": {"email": "a8m"}}`),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported op",
+			input:   []byte(`{"filter": {"age": {"$like": "1%"}}}`),
+			wantErr: true,
+		},
+		{
+			name:    "mismatched type",
+			input:   []byte(`{"filter": {"created_at": {"$gt": "yesterday"}}}`),
+			wantErr: true,
+		},
+		{
+			name:    "limit out of bounds",
+			input:   []byte(`{"limit": 101}`),
+			wantErr: true,
+		},
+		{
+			name:    "unsortable field",
+			input:   []byte(`{"sort": ["created_at"]}`),
+			wantErr: true,
+		},
+		{
+			name:    "invalid cursor value",
+			input:   []byte(`{"sort": ["age"], "after": {"age": "a8m"}}`),
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			input:   []byte(`{"filter": `),
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p, err := NewParser(tt.conf)
-			if err != nil {
-				t.Fatalf("failed to build parser: %v", err)
+			err := p.Validate(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			// Validate must agree with Parse.
+			if _, perr := p.Parse(tt.input); (perr != nil) != (err != nil) {
+				t.Fatalf("validate error: %v, parse error: %v", err, perr)
 			}
-			out := p.GetFields()
-			assertFieldsEqual(t, out, tt.wantOut)
 		})
 	}
 }
 
-func assertFieldsEqual(t *testing.T, got []*Field, want []*Field) {
-	if len(got) != len(want) {
-		t.Fatalf("got %v, wanted %v", got, want)
+func TestDefaultFilterConfig(t *testing.T) {
+	_, err := NewParser(Config{
+		Model: new(struct {
+			Name string `rql:"filter"`
+		}),
+		DefaultFilter: map[string]interface{}{"tenant_id": 1},
+	})
+	if err == nil {
+		t.Fatal("expected an error for an invalid default filter")
 	}
-	for i := 0; i < len(got); i++ {
-		if got[i].Filterable != want[i].Filterable {
-			t.Fatalf("Filterable got:%v want: %v", got[i].Filterable, want[i].Filterable)
-		}
-		if got[i].Sortable != want[i].Sortable {
-			t.Fatalf("Sortable got:%v want: %v", got[i].Sortable, want[i].Sortable)
-		}
-		if got[i].Name != want[i].Name {
-			t.Fatalf("Name got:%v want: %v", got[i].Name, want[i].Name)
-		}
+}
+
+func TestColumnExprConfig(t *testing.T) {
+	_, err := NewParser(Config{
+		Model: new(struct {
+			Name string `rql:"filter"`
+		}),
+		ColumnExpr: map[string]string{"full_name": "(first_name || ' ' || last_name)"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a column expression of an unknown field")
 	}
 }
 
-func compareInterface(a, b interface{}) bool {
-	// If either of the values is nil, handle them first.
-	if a == nil && b != nil {
-		return true // consider nil as the smallest value
+func TestDefaultSortConfig(t *testing.T) {
+	model := new(struct {
+		Name string `rql:"filter,sort"`
+		Age  int    `rql:"filter"`
+	})
+	for _, sort := range [][]string{{"-nmae"}, {"age"}, {"name nullsfoo"}} {
+		if _, err := NewParser(Config{Model: model, DefaultSort: sort}); err == nil {
+			t.Fatalf("expected an error for the invalid default sort %q", sort)
+		}
 	}
-	if a != nil && b == nil {
-		return false
+	if _, err := NewParser(Config{Model: model, DefaultSort: []string{"-name"}}); err != nil {
+		t.Fatalf("unexpected error for a valid default sort: %v", err)
 	}
-	if a == nil && b == nil {
-		return false // doesn't matter which one comes first if both are nil
+	for _, tiebreaker := range [][]string{{"-nmae"}, {"age"}, {"name nullsfoo"}} {
+		if _, err := NewParser(Config{Model: model, SortTiebreaker: tiebreaker}); err == nil {
+			t.Fatalf("expected an error for the invalid sort tiebreaker %q", tiebreaker)
+		}
 	}
-
-	// If they are slices, compare their sorted string representations.
-	if reflect.TypeOf(a).Kind() == reflect.Slice && reflect.TypeOf(b).Kind() == reflect.Slice {
-		return fmt.Sprint(a) < fmt.Sprint(b)
+	if _, err := NewParser(Config{Model: model, SortTiebreaker: []string{"-name"}}); err != nil {
+		t.Fatalf("unexpected error for a valid sort tiebreaker: %v", err)
 	}
+}
 
-	// Otherwise, use the regular string representation for comparison.
-	return fmt.Sprint(a) < fmt.Sprint(b)
+func TestRejectMatchAll(t *testing.T) {
+	model := new(struct {
+		Age       int     `rql:"filter"`
+		DeletedAt *string `rql:"filter"`
+	})
+	tests := []struct {
+		name    string
+		conf    Config
+		input   string
+		wantErr bool
+	}{
+		{name: "empty query", input: `{}`, wantErr: true},
+		{name: "empty filter", input: `{ "filter": {} }`, wantErr: true},
+		{name: "empty or", conf: Config{AllowEmptyGroups: true}, input: `{ "filter": { "$or": [] } }`, wantErr: true},
+		{
+			name:    "nested empty groups",
+			conf:    Config{AllowEmptyGroups: true},
+			input:   `{ "filter": { "$and": [{}, { "$not": { "$or": [] } }] } }`,
+			wantErr: true,
+		},
+		{name: "negated empty filter", input: `{ "filter": {}, "negate": true }`, wantErr: true},
+		{name: "predicate", input: `{ "filter": { "age": 1 } }`},
+		{name: "nested predicate", input: `{ "filter": { "$or": [{}, { "age": 1 }] } }`},
+		{
+			name:  "default filter",
+			conf:  Config{DefaultFilter: map[string]interface{}{"deleted_at": map[string]interface{}{"$null": true}}},
+			input: `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.Model = model
+			tt.conf.RejectMatchAll = true
+			p, err := NewParser(tt.conf)
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			_, err = p.Parse([]byte(tt.input))
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			if tt.wantErr && !errors.Is(err, ErrMatchAll) {
+				t.Fatalf("expected error to wrap ErrMatchAll: %v", err)
+			}
+			if _, err = p.ParseAST([]byte(tt.input)); tt.wantErr != (err != nil) {
+				t.Fatalf("ParseAST want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+		})
+	}
 }
 
-func deepSort(i interface{}) interface{} {
-	switch v := i.(type) {
-	case []interface{}:
-		newSlice := make([]interface{}, len(v))
-		for j, item := range v {
-			newSlice[j] = deepSort(item)
+// TestConcurrentParse runs many goroutines on a shared parser, and should be run with the -race flag.
+// TestFilterOrder checks that the keys of the filter objects and the operators of the fields are rendered in
+// sorted order, regardless of the map iteration order.
+func TestFilterOrder(t *testing.T) {
+	p := MustNewParser(Config{
+		Model: new(struct {
+			Age     int    `rql:"filter"`
+			Name    string `rql:"filter"`
+			Address string `rql:"filter"`
+		}),
+	})
+	input := []byte(`{"filter": {"name": "foo", "age": {"$lt": 20, "$gt": 12}, "$or": [{"address": "DC"}, {"name": "bar", "address": "Marvel"}]}}`)
+	for i := 0; i < 50; i++ {
+		out, err := p.Parse(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := "(address = ? OR address = ? AND name = ?) AND (age > ? AND age < ?) AND name = ?"; out.FilterExp != want {
+			t.Fatalf("filter expr:\n\tgot: %q\n\twant %q", out.FilterExp, want)
+		}
+		if want := []interface{}{"DC", "Marvel", "bar", 12, 20, "foo"}; !reflect.DeepEqual(out.FilterArgs, want) {
+			t.Fatalf("filter args:\n\tgot: %v\n\twant %v", out.FilterArgs, want)
 		}
-		sort.SliceStable(newSlice, func(i, j int) bool {
-			return compareInterface(newSlice[i], newSlice[j])
-		})
-		return newSlice
-	default:
-		return i
 	}
 }
 
-func deepEqualIgnoreOrder(a, b interface{}) error {
-	// Explicitly handle nil cases
-	if (a == nil && b != nil) || (a != nil && b == nil) {
-		return fmt.Errorf("differences found: A=%v, B=%v", a, b)
+func TestParseContext(t *testing.T) {
+	p := MustNewParser(Config{
+		Model: new(struct {
+			Age int `rql:"filter"`
+		}),
+	})
+	input := []byte(`{"filter": {"$or": [{"age": 1}, {"age": 2}]}}`)
+	out, err := p.ParseContext(context.Background(), input)
+	if err != nil || out.FilterExp != "(age = ? OR age = ?)" {
+		t.Fatalf("unexpected result: %v, %v", out, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.ParseContext(ctx, input)
+	var perr *ParseError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &perr) || perr.Code != ErrCanceled {
+		t.Fatalf("want a canceled error, got: %v", err)
 	}
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err := p.ParseQueryContext(ctx, &Query{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want a deadline exceeded error, got: %v", err)
+	}
+}
 
-	sortedA := deepSort(a)
-	sortedB := deepSort(b)
-	if !reflect.DeepEqual(sortedA, sortedB) {
-		return fmt.Errorf("differences found: A=%v, B=%v", sortedA, sortedB)
+func TestConcurrentParse(t *testing.T) {
+	p, err := NewParser(Config{
+		Model: new(struct {
+			ID        int             `rql:"filter,sort"`
+			Age       int             `rql:"filter,sort,min=0,max=150"`
+			Name      string          `rql:"filter,sort"`
+			Tags      []string        `rql:"filter"`
+			Meta      json.RawMessage `rql:"filter,json"`
+			CreatedAt time.Time       `rql:"filter,sort"`
+			DeletedAt *time.Time      `rql:"filter"`
+		}),
+		PositionalParams: true,
+		ParamSymbol:      "$",
+		DefaultFilter:    map[string]interface{}{"deleted_at": map[string]interface{}{"$null": true}},
+		SortTiebreaker:   []string{"id"},
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
 	}
-	return nil
+	inputs := []string{
+		`{}`,
+		`{ "filter": { "age": { "$gt": 10 } }, "sort": ["-age"], "limit": 10 }`,
+		`{ "filter": { "$or": [{ "name": "a8m" }, { "age": { "$between": [1, 5] } }] }, "offset": 20 }`,
+		`{ "filter": { "$and": [{ "tags": { "$has": "go" } }, { "$not": { "id": { "$in": [1, 2, 3] } } }] } }`,
+		`{ "filter": { "meta.labels.0": "go" }, "sort": ["created_at"] }`,
+		`{ "filter": { "created_at": { "$gte": "2020-01-02T00:00:00Z" } }, "sort": ["id"], "after": { "id": 10 } }`,
+		`{ "filter": { "age": 200 } }`,
+		`{ "filter": { "unknown": 1 } }`,
+	}
+	type result struct {
+		params *Params
+		err    error
+	}
+	want := make([]result, len(inputs))
+	for i, in := range inputs {
+		pr, err := p.Parse([]byte(in))
+		want[i] = result{pr, err}
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				i := (g + j) % len(inputs)
+				pr, err := p.Parse([]byte(inputs[i]))
+				if !reflect.DeepEqual(pr, want[i].params) || !reflect.DeepEqual(err, want[i].err) {
+					t.Errorf("input %s:\n\tgot: %+v, %v\n\twant: %+v, %v", inputs[i], pr, err, want[i].params, want[i].err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
 }
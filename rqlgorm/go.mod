@@ -0,0 +1,10 @@
+module github.com/ashtonian/rql/rqlgorm
+
+require (
+	github.com/ashtonian/rql v0.0.0
+	github.com/jinzhu/gorm v1.9.16
+)
+
+replace github.com/ashtonian/rql => ../
+
+go 1.16
@@ -0,0 +1,42 @@
+// Package rqlgorm applies the output of the rql parser on gorm queries.
+// It lives in its own module in order to keep the rql package free of the gorm dependency.
+package rqlgorm
+
+import (
+	"github.com/ashtonian/rql"
+	"github.com/jinzhu/gorm"
+)
+
+// Scope returns a gorm scope that applies the joins, the filter, the cursor, the sort and the pagination
+// of the given params. Empty clauses are skipped. For example:
+//
+//	params, err := parser.Parse(b)
+//	if err != nil {
+//		return err
+//	}
+//	err = db.Scopes(rqlgorm.Scope(params)).Find(&users).Error
+//
+// Note that gorm expects the `?` placeholder, so the parser should use the default ParamSymbol.
+func Scope(p *rql.Params) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, j := range p.Joins {
+			db = db.Joins(j)
+		}
+		if p.FilterExp != "" {
+			db = db.Where(p.FilterExp, p.FilterArgs...)
+		}
+		if p.CursorExp != "" {
+			db = db.Where(p.CursorExp, p.CursorArgs...)
+		}
+		if p.Sort != "" {
+			db = db.Order(p.Sort)
+		}
+		if p.Limit > 0 {
+			db = db.Limit(p.Limit)
+		}
+		if p.Offset > 0 {
+			db = db.Offset(p.Offset)
+		}
+		return db
+	}
+}
@@ -0,0 +1,97 @@
+package rqlgorm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ashtonian/rql"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+type User struct {
+	ID   int    `gorm:"primary_key" rql:"filter,sort"`
+	Age  int    `rql:"filter,sort"`
+	Name string `rql:"filter,sort"`
+}
+
+func TestScope(t *testing.T) {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	if err := db.AutoMigrate(User{}).Error; err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	for i, name := range []string{"a8m", "ariel", "noa", "ron"} {
+		if err := db.Create(&User{ID: i + 1, Age: 20 + i, Name: name}).Error; err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	p, err := rql.NewParser(rql.Config{Model: User{}, DefaultLimit: 25})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	tests := []struct {
+		name    string
+		input   []byte
+		wantIDs []int
+	}{
+		{
+			name:    "empty query",
+			input:   []byte(`{}`),
+			wantIDs: []int{1, 2, 3, 4},
+		},
+		{
+			name: "filter and sort",
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": { "$gte": 22 } },
+						{ "name": { "$like": "a%" } }
+					]
+				},
+				"sort": ["-age"]
+			}`),
+			wantIDs: []int{4, 3, 2, 1},
+		},
+		{
+			name: "pagination",
+			input: []byte(`{
+				"sort": ["name"],
+				"limit": 2,
+				"offset": 1
+			}`),
+			wantIDs: []int{2, 3},
+		},
+		{
+			name: "cursor",
+			input: []byte(`{
+				"filter": { "age": { "$lt": 23 } },
+				"sort": ["id"],
+				"after": { "id": 1 }
+			}`),
+			wantIDs: []int{2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			var users []User
+			if err := db.Scopes(Scope(params)).Find(&users).Error; err != nil {
+				t.Fatalf("failed to query: %v", err)
+			}
+			ids := make([]int, len(users))
+			for i := range users {
+				ids[i] = users[i].ID
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Fatalf("ids:\n\tgot: %v\n\twant %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
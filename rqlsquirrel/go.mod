@@ -0,0 +1,10 @@
+module github.com/ashtonian/rql/rqlsquirrel
+
+require (
+	github.com/Masterminds/squirrel v1.5.4
+	github.com/ashtonian/rql v0.0.0
+)
+
+replace github.com/ashtonian/rql => ../
+
+go 1.16
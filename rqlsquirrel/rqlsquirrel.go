@@ -0,0 +1,75 @@
+// Package rqlsquirrel applies the output of the rql parser on squirrel select builders.
+// It lives in its own module in order to keep the rql package free of the squirrel dependency.
+package rqlsquirrel
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/ashtonian/rql"
+)
+
+// ToSquirrel applies the joins, the filter, the sort and the pagination of the given params on the
+// select builder. The filter is built from the filter tree (and not from FilterExp), so the placeholders
+// are formatted by the builder. For example:
+//
+//	params, err := parser.Parse(b)
+//	if err != nil {
+//		return err
+//	}
+//	sb := rqlsquirrel.ToSquirrel(params, squirrel.Select("*").From("users").PlaceholderFormat(squirrel.Dollar))
+//	query, args, err := sb.ToSql()
+//
+// Note that the cursor expression is not applied.
+func ToSquirrel(p *rql.Params, sb squirrel.SelectBuilder) squirrel.SelectBuilder {
+	for _, j := range p.Joins {
+		sb = sb.JoinClause(j)
+	}
+	if p.Filter != nil && len(p.Filter.Children) > 0 {
+		sb = sb.Where(Sqlizer(p.Filter))
+	}
+	if p.Sort != "" {
+		sb = sb.OrderBy(p.Sort)
+	}
+	if p.Limit > 0 {
+		sb = sb.Limit(uint64(p.Limit))
+	}
+	if p.Offset > 0 {
+		sb = sb.Offset(uint64(p.Offset))
+	}
+	return sb
+}
+
+// Sqlizer returns the squirrel expression of the given filter node.
+func Sqlizer(n *rql.FilterNode) squirrel.Sqlizer {
+	if n.IsPredicate() {
+		return squirrel.Expr(n.Exp, n.Args()...)
+	}
+	if n.Op == rql.NOT {
+		return not{Sqlizer(n.Children[0])}
+	}
+	// groups of a single node are rendered without parentheses, like in FilterExp.
+	if len(n.Children) == 1 {
+		return Sqlizer(n.Children[0])
+	}
+	preds := make([]squirrel.Sqlizer, len(n.Children))
+	for i, c := range n.Children {
+		preds[i] = Sqlizer(c)
+	}
+	if n.Op == rql.OR {
+		return squirrel.Or(preds)
+	}
+	return squirrel.And(preds)
+}
+
+// not negates the wrapped expression.
+type not struct {
+	pred squirrel.Sqlizer
+}
+
+// ToSql implements the squirrel.Sqlizer interface.
+func (n not) ToSql() (string, []interface{}, error) {
+	sql, args, err := n.pred.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	return "NOT (" + sql + ")", args, nil
+}
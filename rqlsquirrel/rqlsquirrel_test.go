@@ -0,0 +1,96 @@
+package rqlsquirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ashtonian/rql"
+)
+
+func TestToSquirrel(t *testing.T) {
+	p, err := rql.NewParser(rql.Config{
+		Model: new(struct {
+			Age  int    `rql:"filter,sort"`
+			Name string `rql:"filter,sort"`
+			City string `rql:"filter,name=city,column=addresses.city,join=JOIN addresses ON addresses.user_id = users.id"`
+		}),
+		DefaultLimit: 25,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	tests := []struct {
+		name     string
+		input    []byte
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:    "empty query",
+			input:   []byte(`{}`),
+			wantSQL: "SELECT * FROM users LIMIT 25",
+		},
+		{
+			name: "filter, sort and pagination",
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "age": { "$between": [1, 5] } },
+						{ "$not": { "name": "a8m" } }
+					]
+				},
+				"sort": ["-age"],
+				"limit": 10,
+				"offset": 20
+			}`),
+			wantSQL:  "SELECT * FROM users WHERE (age BETWEEN $1 AND $2 OR NOT (name = $3)) ORDER BY age desc LIMIT 10 OFFSET 20",
+			wantArgs: []interface{}{1, 5, "a8m"},
+		},
+		{
+			name: "joins",
+			input: []byte(`{
+				"filter": {
+					"$and": [
+						{ "city": "TLV" },
+						{ "age": { "$gt": 10 } }
+					]
+				}
+			}`),
+			wantSQL:  "SELECT * FROM users JOIN addresses ON addresses.user_id = users.id WHERE (addresses.city = $1 AND age > $2) LIMIT 25",
+			wantArgs: []interface{}{"TLV", 10},
+		},
+		{
+			name: "in and nin operators",
+			input: []byte(`{
+				"filter": {
+					"age": { "$in": [1, 2, 3] },
+					"name": { "$nin": ["a8m", "noam"] }
+				}
+			}`),
+			wantSQL:  "SELECT * FROM users WHERE (age IN ($1, $2, $3) AND name NOT IN ($4, $5)) LIMIT 25",
+			wantArgs: []interface{}{1, 2, 3, "a8m", "noam"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			sb := ToSquirrel(params, squirrel.Select("*").From("users").PlaceholderFormat(squirrel.Dollar))
+			sql, args, err := sb.ToSql()
+			if err != nil {
+				t.Fatalf("failed to build sql: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Fatalf("sql:\n\tgot: %q\n\twant %q", sql, tt.wantSQL)
+			}
+			if len(args) != 0 || len(tt.wantArgs) != 0 {
+				if !reflect.DeepEqual(args, tt.wantArgs) {
+					t.Fatalf("args:\n\tgot: %v\n\twant %v", args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package rql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// JSONSchema returns a JSON Schema (draft-07) that describes the valid queries of the parser model, i.e. for
+// client-side validation and autocompletion. It describes the filterable fields with their value types and
+// their available operators, the sortable, selectable and groupable fields, the keyset cursor, and the
+// pagination fields. For example:
+//
+//	{
+//		"type": "object",
+//		"properties": {
+//			"filter": { "$ref": "#/definitions/filter" },
+//			"sort": { "type": "array", "items": { "type": "string", "pattern": "^[+-]?(age|name)( nulls(first|last))?$" } },
+//			"limit": { "type": "integer", "minimum": 1, "maximum": 100 },
+//			...
+//		},
+//		"definitions": {
+//			"filter": {
+//				"type": "object",
+//				"properties": {
+//					"age": { "anyOf": [{ "type": "integer" }, { "type": "object", "properties": { "$gt": { "type": "integer" }, ... } }] },
+//					"$or": { "type": "array", "items": { "$ref": "#/definitions/filter" } },
+//					...
+//				}
+//			}
+//		}
+//	}
+func (p *Parser) JSONSchema() ([]byte, error) {
+	filter := map[string]interface{}{
+		p.op(OR):  map[string]interface{}{"type": "array", "items": schemaRef("filter")},
+		p.op(AND): map[string]interface{}{"type": "array", "items": schemaRef("filter")},
+		p.op(NOT): schemaRef("filter"),
+	}
+	jsonPaths := make(map[string]interface{})
+	after := make(map[string]interface{})
+	var sortable, groupable []string
+	selectable := make([]string, 0, len(p.fields)+1)
+	if !p.DenySelectWildcard {
+		selectable = append(selectable, "*")
+	}
+	for _, f := range p.GetFields() {
+		if f.Sortable {
+			sortable = append(sortable, regexp.QuoteMeta(f.Name))
+			after[f.Name] = valueSchema(f.FieldMeta, f.Type)
+		}
+		if f.Groupable {
+			groupable = append(groupable, f.Name)
+		}
+		if f.Selectable || len(p.selectable) == 0 {
+			selectable = append(selectable, f.Name)
+		}
+		if !f.Filterable {
+			continue
+		}
+		// the keys of JSON fields are their paths, i.e. "metadata.tier".
+		if f.JSON {
+			jsonPaths["^"+regexp.QuoteMeta(f.Name+jsonPathSep)] = map[string]interface{}{}
+		}
+		if len(f.AvailableOps) > 0 {
+			filter[f.Name] = p.fieldSchema(f.FieldMeta)
+		}
+	}
+	filterSchema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           filter,
+		"additionalProperties": false,
+	}
+	if len(jsonPaths) > 0 {
+		filterSchema["patternProperties"] = jsonPaths
+	}
+	sortItem := map[string]interface{}{"type": "string"}
+	if len(sortable) > 0 {
+		sortItem["pattern"] = "^[+-]?(" + strings.Join(sortable, "|") + ")( nulls(first|last))?$"
+	} else {
+		sortItem["enum"] = []string{}
+	}
+	sortSchema := map[string]interface{}{"type": "array", "items": sortItem}
+	if p.MaxSortFields > 0 {
+		sortSchema["maxItems"] = p.MaxSortFields
+	}
+	offsetSchema := map[string]interface{}{"type": "integer", "minimum": 0}
+	if p.OffsetMaxValue > 0 {
+		offsetSchema["maximum"] = p.OffsetMaxValue
+	}
+	limitSchema := map[string]interface{}{"type": "integer", "minimum": 1, "maximum": p.LimitMaxValue}
+	if groupable == nil {
+		groupable = []string{}
+	}
+	schema := map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"filter":   schemaRef("filter"),
+			"negate":   map[string]interface{}{"type": "boolean"},
+			"sort":     sortSchema,
+			"select":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"enum": selectable}},
+			"distinct": map[string]interface{}{"type": "boolean"},
+			"group":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"enum": groupable}},
+			"after":    map[string]interface{}{"type": "object", "properties": after, "additionalProperties": false},
+			"limit":    limitSchema,
+			"offset":   offsetSchema,
+			"page":     map[string]interface{}{"type": "integer", "minimum": 1},
+			"pageSize": limitSchema,
+		},
+		// unknown fields are rejected by the parser.
+		"additionalProperties": false,
+		"definitions": map[string]interface{}{
+			"filter": filterSchema,
+		},
+	}
+	return json.Marshal(schema)
+}
+
+// fieldSchema returns the schema of the given filterable field. i.e. a bare value, or an object of its operators.
+func (p *Parser) fieldSchema(f *FieldMeta) map[string]interface{} {
+	value := valueSchema(f, f.Type)
+	ops := make(map[string]interface{}, len(f.AvailableOps))
+	for _, name := range f.AvailableOps {
+		switch op := Op(strings.TrimPrefix(name, p.OpPrefix)); {
+		case op == LIKE || op == ILIKE || op == STARTSWITH || op == ENDSWITH || op == INCLUDES || op == SEARCH || op == INSUBNET:
+			ops[name] = map[string]interface{}{"type": "string"}
+		case op == CONTAINS && f.Type.Kind() == reflect.String:
+			ops[name] = map[string]interface{}{"type": "string"}
+		case op == IN || op == NIN || op == CONTAINS:
+			ops[name] = map[string]interface{}{"type": "array", "items": value, "minItems": 1}
+		case op == BETWEEN:
+			ops[name] = map[string]interface{}{"type": "array", "items": value, "minItems": 2, "maxItems": 2}
+		case op == NULL:
+			ops[name] = map[string]interface{}{"type": "boolean"}
+		case op == EQ || op == NEQ:
+			ops[name] = nullable(f, value)
+		case op == SIZE:
+			ops[name] = map[string]interface{}{"type": "integer", "minimum": 0}
+		case op == OVERLAPS:
+			ops[name] = map[string]interface{}{
+				"type":                 "object",
+				"properties":           map[string]interface{}{"start": value, "end": value},
+				"required":             []string{"start", "end"},
+				"additionalProperties": false,
+			}
+		default:
+			ops[name] = value
+		}
+	}
+	terms := map[string]interface{}{
+		"type":                 "object",
+		"properties":           ops,
+		"additionalProperties": false,
+	}
+	// range fields accept only operator objects.
+	if f.Range != nil {
+		return terms
+	}
+	return map[string]interface{}{"anyOf": []interface{}{nullable(f, value), terms}}
+}
+
+// nullable returns the given value schema, that accepts null as well if the field is nullable.
+func nullable(f *FieldMeta, value map[string]interface{}) map[string]interface{} {
+	if !f.Nullable {
+		return value
+	}
+	return map[string]interface{}{"anyOf": []interface{}{value, map[string]interface{}{"type": "null"}}}
+}
+
+// valueSchema returns the schema of a single value of the given field type. The values of array
+// fields are their elements.
+func valueSchema(f *FieldMeta, t reflect.Type) map[string]interface{} {
+	t = indirect(t)
+	if f.JSON {
+		return map[string]interface{}{}
+	}
+	// big numbers are accepted as strings as well, as JSON numbers are limited in precision.
+	if isBig(t) {
+		if t == reflect.TypeOf(big.Int{}) {
+			return map[string]interface{}{"type": []string{"integer", "string"}}
+		}
+		return map[string]interface{}{"type": []string{"number", "string"}}
+	}
+	if isUnmarshaler(t) {
+		// values of JSON unmarshalers (i.e. decimal.Decimal) are not limited to strings.
+		if reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"type": "string"}
+	}
+	s := make(map[string]interface{})
+	switch t.Kind() {
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.String:
+		s["type"] = "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s["type"] = "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s["type"], s["minimum"] = "integer", 0
+	case reflect.Float32, reflect.Float64:
+		s["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		return valueSchema(f, t.Elem())
+	case reflect.Struct:
+		switch reflect.Zero(t).Interface().(type) {
+		case sql.NullBool:
+			s["type"] = "boolean"
+		case sql.NullString:
+			s["type"] = "string"
+		case sql.NullInt64:
+			s["type"] = "integer"
+		case sql.NullFloat64:
+			s["type"] = "number"
+		default:
+			s["type"] = "string"
+			// the date-time format of JSON Schema is RFC 3339.
+			if layouts := f.timeLayouts(); f.timeFn == nil && len(layouts) == 1 && (layouts[0] == time.RFC3339 || layouts[0] == time.RFC3339Nano) {
+				s["format"] = "date-time"
+			}
+		}
+	}
+	if isNumber(t) {
+		if min, ok := f.Min.(float64); ok {
+			s["minimum"] = min
+		}
+		if max, ok := f.Max.(float64); ok {
+			s["maximum"] = max
+		}
+	}
+	return s
+}
+
+// schemaRef returns a reference to the given definition.
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/definitions/" + name}
+}
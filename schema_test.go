@@ -0,0 +1,163 @@
+package rql
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJSONSchema(t *testing.T) {
+	p := MustNewParser(Config{
+		Model: new(struct {
+			ID        int             `rql:"filter,sort,ops=eq|in"`
+			Age       uint            `rql:"filter,sort,max=150"`
+			Name      string          `rql:"filter"`
+			Admin     bool            `rql:"filter"`
+			CreatedAt time.Time       `rql:"filter,sort"`
+			Nickname  *string         `rql:"filter,group"`
+			Metadata  json.RawMessage `rql:"filter,json"`
+			Password  string
+		}),
+		LimitMaxValue: 100,
+		FieldSep:      "__",
+	})
+	b, err := p.JSONSchema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	// the parser accepts the keys that are described by the schema.
+	if _, err := p.Parse([]byte(`{"filter": {"metadata.tier": "gold", "nickname": null}, "group": ["nickname"], "page": 2, "pageSize": 10}`)); err != nil {
+		t.Fatalf("unexpected error for a query that matches the schema: %v", err)
+	}
+	tests := []struct {
+		name string
+		path []string
+		want interface{}
+	}{
+		{
+			name: "limit bounds",
+			path: []string{"properties", "limit"},
+			want: map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 100.0},
+		},
+		{
+			name: "selectable fields",
+			path: []string{"properties", "select", "items", "enum"},
+			want: []interface{}{"*", "admin", "age", "created_at", "id", "metadata", "name", "nickname"},
+		},
+		{
+			name: "sortable fields",
+			path: []string{"properties", "sort", "items", "pattern"},
+			want: "^[+-]?(age|created_at|id)( nulls(first|last))?$",
+		},
+		{
+			name: "ops whitelist",
+			path: []string{"definitions", "filter", "properties", "id", "anyOf"},
+			want: []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"$eq": map[string]interface{}{"type": "integer"},
+						"$in": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}, "minItems": 1.0},
+					},
+					"additionalProperties": false,
+				},
+			},
+		},
+		{
+			name: "unsigned field with max",
+			path: []string{"definitions", "filter", "properties", "age", "anyOf", "0"},
+			want: map[string]interface{}{"type": "integer", "minimum": 0.0, "maximum": 150.0},
+		},
+		{
+			name: "string ops",
+			path: []string{"definitions", "filter", "properties", "name", "anyOf", "1", "properties", "$like"},
+			want: map[string]interface{}{"type": "string"},
+		},
+		{
+			name: "time field",
+			path: []string{"definitions", "filter", "properties", "created_at", "anyOf", "0"},
+			want: map[string]interface{}{"type": "string", "format": "date-time"},
+		},
+		{
+			name: "bool field",
+			path: []string{"definitions", "filter", "properties", "admin", "anyOf", "0"},
+			want: map[string]interface{}{"type": "boolean"},
+		},
+		{
+			name: "non-filterable field",
+			path: []string{"definitions", "filter", "properties", "password"},
+			want: nil,
+		},
+		{
+			name: "nullable field",
+			path: []string{"definitions", "filter", "properties", "nickname", "anyOf", "0"},
+			want: map[string]interface{}{"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "null"},
+			}},
+		},
+		{
+			name: "nullable field equality",
+			path: []string{"definitions", "filter", "properties", "nickname", "anyOf", "1", "properties", "$neq", "anyOf", "1"},
+			want: map[string]interface{}{"type": "null"},
+		},
+		{
+			name: "json paths",
+			path: []string{"definitions", "filter", "patternProperties"},
+			want: map[string]interface{}{`^metadata\.`: map[string]interface{}{}},
+		},
+		{
+			name: "groupable fields",
+			path: []string{"properties", "group", "items", "enum"},
+			want: []interface{}{"nickname"},
+		},
+		{
+			name: "cursor fields",
+			path: []string{"properties", "after", "properties", "id"},
+			want: map[string]interface{}{"type": "integer"},
+		},
+		{
+			name: "page size",
+			path: []string{"properties", "pageSize"},
+			want: map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 100.0},
+		},
+		{
+			name: "unknown fields",
+			path: []string{"additionalProperties"},
+			want: false,
+		},
+		{
+			name: "logical operators",
+			path: []string{"definitions", "filter", "properties", "$or", "items"},
+			want: map[string]interface{}{"$ref": "#/definitions/filter"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got interface{} = schema
+			for _, k := range tt.path {
+				switch v := got.(type) {
+				case map[string]interface{}:
+					got = v[k]
+				case []interface{}:
+					var i int
+					if err := json.Unmarshal([]byte(k), &i); err != nil || i >= len(v) {
+						t.Fatalf("invalid index %q", k)
+					}
+					got = v[i]
+				default:
+					got = nil
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("schema at %v = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package rql
+
+import (
+	"database/sql"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseValues parses the given URL query values into a Param object. It is useful for GET endpoints
+// that accept the query in the query string instead of the request body. The filter is expressed
+// using bracketed keys, and arrays (i.e. `$or` and `$and`) using indexed keys. For example:
+//
+//	filter[age][$gt]=10&filter[$or][0][city]=TLV&filter[$or][1][city]=NYC&sort=-name&limit=20
+//
+// is equivalent to the following JSON query:
+//
+//	{
+//		"filter": {
+//			"age": { "$gt": 10 },
+//			"$or": [{ "city": "TLV" }, { "city": "NYC" }]
+//		},
+//		"sort": ["-name"],
+//		"limit": 20
+//	}
+//
+// The keyset pagination cursor is expressed the same way, i.e. after[id]=1000. Bool fields without a value
+// (i.e. filter[admin]) are true.
+// The `sort`, `select` and `group` keys can be repeated, or contain a comma-separated list of fields.
+func (p *Parser) ParseValues(v url.Values) (*Params, error) {
+	q, err := p.valuesQuery(v)
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseQuery(q)
+}
+
+// valuesQuery decodes the given URL query values into a Query object.
+func (p *Parser) valuesQuery(v url.Values) (*Query, error) {
+	q := &Query{}
+	filter := make(map[string]interface{})
+	after := make(map[string]interface{})
+	// sort the keys in order to have a deterministic output for the same input.
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var err error
+		switch s := v.Get(k); {
+		case k == Limit:
+			q.Limit, err = strconv.Atoi(s)
+		case k == Offset:
+			q.Offset, err = strconv.Atoi(s)
+		case k == "page":
+			q.Page, err = strconv.Atoi(s)
+		case k == "pageSize":
+			q.PageSize, err = strconv.Atoi(s)
+		case k == "sort":
+			q.Sort = splitValues(v[k])
+		case k == "select":
+			q.Select = splitValues(v[k])
+		case k == "group":
+			q.Group = splitValues(v[k])
+		case k == "distinct":
+			q.Distinct, err = strconv.ParseBool(s)
+		case k == "negate":
+			q.Negate, err = strconv.ParseBool(s)
+		case strings.HasPrefix(k, "filter["):
+			err = setValue(filter, k[len("filter"):], s)
+		case strings.HasPrefix(k, "after["):
+			err = setValue(after, k[len("after"):], s)
+		default:
+			return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding values to *Query: unknown field " + strconv.Quote(k)}
+		}
+		if err != nil {
+			return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding values to *Query: key " + strconv.Quote(k) + ": " + err.Error()}
+		}
+	}
+	if len(filter) > 0 {
+		m, ok := arrays(filter).(map[string]interface{})
+		if !ok {
+			return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding values to *Query: filter must be type object"}
+		}
+		q.Filter = p.coerceFilter(m)
+	}
+	for k, v := range after {
+		if f := p.fields[p.key(k)]; f != nil && isString(v) {
+			after[k] = coerceValue(f.Type, v.(string))
+		}
+	}
+	if len(after) > 0 {
+		q.After = after
+	}
+	return q, nil
+}
+
+// splitValues splits the given values by comma, and drops the empty ones.
+func splitValues(vs []string) []string {
+	var fields []string
+	for _, v := range vs {
+		for _, s := range strings.Split(v, ",") {
+			if s != "" {
+				fields = append(fields, s)
+			}
+		}
+	}
+	return fields
+}
+
+// setValue sets the value in the given tree according to the bracketed path. i.e. "[a][b]".
+func setValue(m map[string]interface{}, path, v string) error {
+	for {
+		end := strings.IndexByte(path, ']')
+		if path == "" || path[0] != '[' || end == -1 {
+			return &ParseError{Code: ErrInvalidQuery, msg: "invalid key format"}
+		}
+		k, rest := path[1:end], path[end+1:]
+		if rest == "" {
+			m[k] = v
+			return nil
+		}
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			if _, exists := m[k]; exists {
+				return &ParseError{Code: ErrInvalidQuery, msg: "conflicting value for " + strconv.Quote(k)}
+			}
+			next = make(map[string]interface{})
+			m[k] = next
+		}
+		m, path = next, rest
+	}
+}
+
+// arrays converts the objects in the given tree that their keys are a sequence of indexes
+// (i.e. "0", "1", ...) to arrays.
+func arrays(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	for k, e := range m {
+		m[k] = arrays(e)
+	}
+	a := make([]interface{}, len(m))
+	for k, e := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || a[i] != nil {
+			return m
+		}
+		a[i] = e
+	}
+	if len(a) == 0 {
+		return m
+	}
+	return a
+}
+
+// coerceFilter converts the string values in the given filter to the types that are expected by
+// their fields. For example, "10" is converted to float64 for numeric fields, like the JSON decoder does.
+func (p *Parser) coerceFilter(m map[string]interface{}) map[string]interface{} {
+	for k, v := range m {
+		// keys are resolved the same way the parser does, i.e. trimmed if TrimKeys is set.
+		rk := p.key(k)
+		switch f := p.fields[rk]; {
+		case rk == p.op(OR) || rk == p.op(AND):
+			terms, _ := v.([]interface{})
+			for _, t := range terms {
+				if mt, ok := t.(map[string]interface{}); ok {
+					p.coerceFilter(mt)
+				}
+			}
+		case rk == p.op(NOT):
+			if mt, ok := v.(map[string]interface{}); ok {
+				p.coerceFilter(mt)
+			}
+		case f == nil:
+		case isString(v):
+			m[k] = coerceValue(f.Type, v.(string))
+		default:
+			terms, _ := v.(map[string]interface{})
+			for opName, opVal := range terms {
+				terms[opName] = p.coerceOp(f, Op(strings.TrimPrefix(opName, p.OpPrefix)), opVal)
+			}
+		}
+	}
+	return m
+}
+
+// coerceOp converts the operand of the given operator.
+func (p *Parser) coerceOp(f *Field, op Op, v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		switch op {
+		case NULL:
+			return coerceValue(reflect.TypeOf(true), v)
+		case SIZE:
+			return coerceValue(reflect.TypeOf(0), v)
+		}
+		return coerceValue(f.Type, v)
+	case []interface{}:
+		for i := range v {
+			v[i] = p.coerceOp(f, op, v[i])
+		}
+	}
+	return v
+}
+
+// coerceValue converts the given string to the JSON type that is expected for the given type.
+// If the string is not convertible, it is returned as is, and the validation fails later.
+func coerceValue(t reflect.Type, s string) interface{} {
+	switch t.Kind() {
+	case reflect.Bool:
+		// a key without a value is a shorthand for true, i.e. "filter[admin]".
+		if s == "" {
+			return true
+		}
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case reflect.Slice, reflect.Array:
+		return coerceValue(indirect(t.Elem()), s)
+	case reflect.Struct:
+		switch reflect.Zero(t).Interface().(type) {
+		case sql.NullBool:
+			return coerceValue(reflect.TypeOf(true), s)
+		case sql.NullInt64, sql.NullFloat64:
+			return coerceValue(reflect.TypeOf(0.0), s)
+		}
+	}
+	return s
+}
+
+func isString(v interface{}) bool {
+	_, ok := v.(string)
+	return ok
+}
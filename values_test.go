@@ -0,0 +1,204 @@
+package rql
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseValues(t *testing.T) {
+	model := new(struct {
+		Age       int        `rql:"filter,sort"`
+		Name      string     `rql:"filter,sort"`
+		Admin     bool       `rql:"filter"`
+		City      string     `rql:"filter,group"`
+		Score     float64    `rql:"filter"`
+		CreatedAt time.Time  `rql:"filter"`
+		DeletedAt *time.Time `rql:"filter"`
+		Scores    []int      `rql:"filter"`
+	})
+	tests := []struct {
+		name    string
+		input   string
+		json    []byte
+		wantErr bool
+	}{
+		{
+			name:  "simple",
+			input: "filter[age][$gt]=10&filter[name]=a8m&filter[admin]=true&sort=-name&limit=20&offset=5",
+			json: []byte(`{
+				"filter": {
+					"age": { "$gt": 10 },
+					"name": "a8m",
+					"admin": true
+				},
+				"sort": ["-name"],
+				"limit": 20,
+				"offset": 5
+			}`),
+		},
+		{
+			name:  "nested or and and",
+			input: "filter[$or][0][city]=DC&filter[$or][1][city]=TLV&filter[$and][0][score][$gte]=1.5&filter[$and][1][$or][0][age]=10&filter[$and][1][$or][1][age]=11",
+			json: []byte(`{
+				"filter": {
+					"$or": [{ "city": "DC" }, { "city": "TLV" }],
+					"$and": [
+						{ "score": { "$gte": 1.5 } },
+						{ "$or": [{ "age": 10 }, { "age": 11 }] }
+					]
+				}
+			}`),
+		},
+		{
+			name:  "arrays and time values",
+			input: "filter[age][$between][0]=10&filter[age][$between][1]=20&filter[created_at][$gt]=2018-01-14T06:05:48.839Z&filter[deleted_at][$null]=true",
+			json: []byte(`{
+				"filter": {
+					"age": { "$between": [10, 20] },
+					"created_at": { "$gt": "2018-01-14T06:05:48.839Z" },
+					"deleted_at": { "$null": true }
+				}
+			}`),
+		},
+		{
+			name:  "not",
+			input: "filter[$not][age][$gt]=10&filter[$or][0][$not][admin]=true&filter[$or][1][score]=1.5",
+			json: []byte(`{
+				"filter": {
+					"$not": { "age": { "$gt": 10 } },
+					"$or": [{ "$not": { "admin": true } }, { "score": 1.5 }]
+				}
+			}`),
+		},
+		{
+			name:  "trimmed keys",
+			input: "filter[%20age%20][$gt]=10&filter[$not][%20score][$lt]=1.5",
+			json: []byte(`{
+				"filter": {
+					" age ": { "$gt": 10 },
+					"$not": { " score": { "$lt": 1.5 } }
+				}
+			}`),
+		},
+		{
+			name:  "whole float on int field",
+			input: "filter[age]=10.0",
+			json:  []byte(`{"filter": {"age": 10}}`),
+		},
+		{
+			name:  "bool shorthand",
+			input: "filter[admin]&filter[$or][0][deleted_at][$null]&filter[$or][1][admin]=false",
+			json: []byte(`{
+				"filter": {
+					"admin": true,
+					"$or": [{ "deleted_at": { "$null": true } }, { "admin": false }]
+				}
+			}`),
+		},
+		{
+			name:  "string that looks like a number",
+			input: "filter[name]=10&select=name,age&select=city&distinct=true&group=city&sort=name&sort=-age",
+			json: []byte(`{
+				"filter": { "name": "10" },
+				"select": ["name", "age", "city"],
+				"distinct": true,
+				"group": ["city"],
+				"sort": ["name", "-age"]
+			}`),
+		},
+		{
+			name:  "scalar on array field",
+			input: "filter[scores]=10&filter[age][$in][0]=1",
+			json: []byte(`{
+				"filter": {
+					"scores": 10,
+					"age": { "$in": [1] }
+				}
+			}`),
+		},
+		{
+			name:  "cursor",
+			input: "sort=-age&after[age]=30",
+			json: []byte(`{
+				"sort": ["-age"],
+				"after": { "age": 30 }
+			}`),
+		},
+		{
+			name:  "negate",
+			input: "filter[name]=a8m&negate=true",
+			json: []byte(`{
+				"filter": { "name": "a8m" },
+				"negate": true
+			}`),
+		},
+		{
+			name:    "invalid type",
+			input:   "filter[age]=a8m",
+			wantErr: true,
+		},
+		{
+			name:  "page and page size",
+			input: "page=2&pageSize=10",
+			json: []byte(`{
+				"page": 2,
+				"pageSize": 10
+			}`),
+		},
+		{
+			name:    "conflicting offset and page",
+			input:   "offset=10&page=2",
+			wantErr: true,
+		},
+		{
+			name:    "invalid limit",
+			input:   "limit=ten",
+			wantErr: true,
+		},
+		{
+			name:    "invalid distinct",
+			input:   "distinct=yes",
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			input:   "where[age]=10",
+			wantErr: true,
+		},
+		{
+			name:    "filter array",
+			input:   "filter[0]=10",
+			wantErr: true,
+		},
+		{
+			name:    "invalid key format",
+			input:   "filter[age[$gt]=10",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(Config{Model: model, TrimKeys: true, Log: t.Logf})
+			if err != nil {
+				t.Fatalf("failed to build parser: %v", err)
+			}
+			v, err := url.ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse query string: %v", err)
+			}
+			out, err := p.ParseValues(v)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			want, err := p.Parse(tt.json)
+			if err != nil {
+				t.Fatalf("failed to parse json: %v", err)
+			}
+			assertParams(t, out, want)
+		})
+	}
+}
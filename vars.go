@@ -0,0 +1,81 @@
+package rql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ContextVarPrefix is the prefix of the placeholders in the filter values that are resolved by
+// ParseWithContextVars. For example: { "owner_id": "$ctx.user_id" }.
+const ContextVarPrefix = "$ctx."
+
+// ParseWithContextVars is like Parse, but resolves the context placeholders in the filter values
+// (i.e. "$ctx.user_id") from the given server-supplied variables before parsing. It returns an error
+// if a placeholder references an unknown variable. The variables are used by their JSON representation,
+// i.e. an int is treated like a JSON number, and a time.Time like an RFC 3339 string. For example:
+//
+//	params, err := parser.ParseWithContextVars(savedFilter, map[string]interface{}{
+//		"user_id": user.ID,
+//		"now":     time.Now(),
+//	})
+//
+// Placeholders are resolved in any input, so it should be used only for trusted (i.e. saved) queries.
+func (p *Parser) ParseWithContextVars(b []byte, vars map[string]interface{}) (*Params, error) {
+	q := &Query{}
+	if err := q.UnmarshalJSON(b); err != nil {
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: "decoding buffer to *Query: " + err.Error()}
+	}
+	filter, err := resolveVars(q.Filter, vars)
+	if err != nil {
+		return nil, err
+	}
+	q.Filter = filter.(map[string]interface{})
+	return p.ParseQuery(q)
+}
+
+// resolveVars replaces the context placeholders in the given filter value with their variables.
+func resolveVars(v interface{}, vars map[string]interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			r, err := resolveVars(e, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = r
+		}
+	case []interface{}:
+		for i, e := range v {
+			r, err := resolveVars(e, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = r
+		}
+	case string:
+		if !strings.HasPrefix(v, ContextVarPrefix) {
+			return v, nil
+		}
+		name := strings.TrimPrefix(v, ContextVarPrefix)
+		cv, ok := vars[name]
+		if !ok {
+			return nil, &ParseError{Code: ErrInvalidQuery, msg: fmt.Sprintf("unknown context variable %q", name)}
+		}
+		return jsonValue(cv, name)
+	}
+	return v, nil
+}
+
+// jsonValue returns the JSON representation of the given context variable, as decoded by the parser.
+func jsonValue(v interface{}, name string) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: fmt.Sprintf("encoding context variable %q: %v", name, err), err: err}
+	}
+	var jv interface{}
+	if err := json.Unmarshal(b, &jv); err != nil {
+		return nil, &ParseError{Code: ErrInvalidQuery, msg: fmt.Sprintf("decoding context variable %q: %v", name, err), err: err}
+	}
+	return jv, nil
+}
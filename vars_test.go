@@ -0,0 +1,97 @@
+package rql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWithContextVars(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	vars := map[string]interface{}{
+		"user_id": 42,
+		"now":     now,
+		"teams":   []string{"a", "b"},
+	}
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr bool
+		wantOut *Params
+	}{
+		{
+			name: "scalar variable",
+			input: []byte(`{
+				"filter": { "owner_id": "$ctx.user_id" }
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "owner_id = ?",
+				FilterArgs: []interface{}{42},
+			},
+		},
+		{
+			name: "variable with mismatched type",
+			input: []byte(`{
+				"filter": {
+					"team": { "$in": ["c", "$ctx.user_id"] }
+				}
+			}`),
+			wantErr: true,
+		},
+		{
+			name: "variables in operators",
+			input: []byte(`{
+				"filter": {
+					"$or": [
+						{ "created_at": { "$lt": "$ctx.now" } },
+						{ "team": { "$in": "$ctx.teams" } },
+						{ "owner_id": { "$between": [1, "$ctx.user_id"] } }
+					]
+				}
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "(created_at < ? OR team IN (?, ?) OR owner_id BETWEEN ? AND ?)",
+				FilterArgs: []interface{}{now, "a", "b", 1, 42},
+			},
+		},
+		{
+			name: "literal values",
+			input: []byte(`{
+				"filter": { "team": "ctx.user_id" }
+			}`),
+			wantOut: &Params{
+				Limit:      25,
+				FilterExp:  "team = ?",
+				FilterArgs: []interface{}{"ctx.user_id"},
+			},
+		},
+		{
+			name: "unknown variable",
+			input: []byte(`{
+				"filter": { "owner_id": "$ctx.org_id" }
+			}`),
+			wantErr: true,
+		},
+	}
+	p, err := NewParser(Config{
+		Model: new(struct {
+			OwnerID   int       `rql:"filter"`
+			Team      string    `rql:"filter"`
+			CreatedAt time.Time `rql:"filter"`
+		}),
+		Log: t.Logf,
+	})
+	if err != nil {
+		t.Fatalf("failed to build parser: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.ParseWithContextVars(tt.input, vars)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("want: %v\ngot:%v\nerr: %v", tt.wantErr, err != nil, err)
+			}
+			assertParams(t, out, tt.wantOut)
+		})
+	}
+}